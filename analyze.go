@@ -0,0 +1,524 @@
+package slang
+
+import (
+	"fmt"
+	"io"
+)
+
+// analyzed is a form analyzed by analyze. It evaluates the form in an environment. Special forms
+// are dispatched and the structure of the form is walked once, when it is analyzed.
+type analyzed func(env *Env) (LangType, error)
+
+// Analysis is an expression analyzed by Analyze. Use Run to evaluate it.
+type Analysis struct {
+	expr     LangType
+	analyzed analyzed
+}
+
+// Analyze analyzes an expression once, so it can be evaluated many times with Run. Evaluate
+// analyzes its expression again each time it is called.
+func Analyze(expr LangType) *Analysis {
+	return &Analysis{expr: expr, analyzed: analyze(expr, false, nil)}
+}
+
+// Run evaluates the analyzed expression in env, like Evaluate. If env has a Hook, the expression is
+// evaluated by walking it instead.
+func (a *Analysis) Run(env Env) (LangType, error) {
+	if env.hooks != nil && env.hooks.hook != nil {
+		return evaluateHooked(a.expr, env)
+	}
+	return a.analyzed(&env)
+}
+
+// tailCall is the application of an analyzed Lambda in tail position. Analyzed procedure bodies
+// return it to the application that called them, which applies it, so tail calls do not grow the Go
+// stack.
+type tailCall struct {
+	lambda Lambda
+	args   []LangType
+}
+
+// analyze analyzes expr in scope, the local variables of the procedures it is in. tail is true if
+// expr is in tail position of a procedure body; only then may the analyzed form return a *tailCall.
+func analyze(expr LangType, tail bool, scope *lexicalScope) analyzed {
+	switch t := expr.(type) {
+	case Symbol:
+		return analyzeSymbol(t, scope)
+	case Vector:
+		items := analyzeAll(t, scope)
+		return func(env *Env) (LangType, error) {
+			return evaluateAnalyzed(items, env)
+		}
+	case Map:
+		// keys are literals and are not evaluated
+		values := analyzeAll(t.values, scope)
+		return func(env *Env) (LangType, error) {
+			evaluated, err := evaluateAnalyzed(values, env)
+			if err != nil {
+				return nil, err
+			}
+			return Map{keys: t.keys, values: evaluated, index: t.index}, nil
+		}
+	case List:
+		if t.Len() > 0 {
+			return analyzeList(t, tail, scope)
+		}
+	}
+	return func(env *Env) (LangType, error) {
+		return expr, nil
+	}
+}
+
+// lexicalScope is the local variables of a procedure being analyzed. Symbols that are not local to an
+// enclosing procedure are looked up in the environment the code runs in.
+type lexicalScope struct {
+	names []Symbol
+	outer *lexicalScope
+}
+
+// resolve returns the lexical address of a local variable: the number of scopes out it is local
+// to, and the index of its slot.
+func (s *lexicalScope) resolve(symbol Symbol) (depth, index int, isLocal bool) {
+	for ; s != nil; s = s.outer {
+		for i, name := range s.names {
+			if name == symbol {
+				return depth, i, true
+			}
+		}
+		depth++
+	}
+	return depth, -1, false
+}
+
+// analyzeSymbol analyzes a reference to a symbol. Local variables are addressed by the depth of the
+// frame they are local to and the index of their slot; other symbols are looked up by name in the
+// environment enclosing the frames of the procedures.
+func analyzeSymbol(symbol Symbol, scope *lexicalScope) analyzed {
+	depth, index, isLocal := scope.resolve(symbol)
+	if !isLocal {
+		return func(env *Env) (LangType, error) {
+			return env.global(depth, symbol)
+		}
+	}
+	return func(env *Env) (LangType, error) {
+		frame := env
+		for i := depth; i > 0; i-- {
+			frame = frame.outer
+		}
+		value := frame.slots[index]
+		if _, isUnbound := value.(unbound); isUnbound {
+			// the definition has not been evaluated; Evaluate looks in the enclosing frames
+			return frame.outer.Get(symbol)
+		}
+		return value, nil
+	}
+}
+
+// global looks up a symbol that is not local to the depth frames of procedures enclosing env. Their
+// slots are skipped; only the symbols defined in them by forms evaluated with Evaluate are looked up.
+func (env *Env) global(depth int, symbol Symbol) (LangType, error) {
+	for ; depth > 0; depth-- {
+		if value, exists := env.frame[symbol]; exists {
+			return value, nil
+		}
+		env = env.outer
+	}
+	return env.Get(symbol)
+}
+
+func analyzeAll(exprs []LangType, scope *lexicalScope) []analyzed {
+	analyzedExprs := make([]analyzed, len(exprs))
+	for i, expr := range exprs {
+		analyzedExprs[i] = analyze(expr, false, scope)
+	}
+	return analyzedExprs
+}
+
+func evaluateAnalyzed(exprs []analyzed, env *Env) (Vector, error) {
+	values := make(Vector, len(exprs))
+	for i, expr := range exprs {
+		value, err := expr(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// failed returns an analyzed form that fails with an error when it is evaluated, as Evaluate does
+// for malformed forms.
+func failed(format string, args ...interface{}) analyzed {
+	err := fmt.Errorf(format, args...)
+	return func(env *Env) (LangType, error) {
+		return nil, err
+	}
+}
+
+// special returns an analyzed form that evaluates form with the evaluator of a special form.
+func special(form List, evaluate func(List, Env) (LangType, error)) analyzed {
+	return func(env *Env) (LangType, error) {
+		return evaluate(form, *env)
+	}
+}
+
+func analyzeList(form List, tail bool, scope *lexicalScope) analyzed {
+	operator := form.First()
+	symbol, isSymbol := operator.(Symbol)
+	if _, isList := operator.(List); !isSymbol && !isList {
+		return failed("'%s' is not applicable", operator)
+	}
+
+	operands := form.Rest().(List)
+	switch symbol {
+	case "quote":
+		if operands.Len() != 1 {
+			return failed("Invalid number of arguments - expected 1 argument")
+		}
+		quoted := operands.First()
+		return func(env *Env) (LangType, error) {
+			return quoted, nil
+		}
+	case "if":
+		return analyzeIf(operands, tail, scope)
+	case "begin":
+		if operands.Len() < 1 {
+			return failed("Invalid form for begin")
+		}
+		return analyzeBody(operands.Items(), tail, scope)
+	case "define":
+		return analyzeDefine(form, operands, scope)
+	case "lambda":
+		if operands.Len() < 2 {
+			return failed("Invalid number of arguments - expected at least 2 arguments")
+		}
+		params, isVec := operands.First().(Vector)
+		if !isVec {
+			return failed("First argument to lambda must be a vector")
+		}
+		return analyzeLambda(params, operands.Rest().(List), form.source, scope)
+	case "with-open":
+		return analyzeWithOpen(operands, scope)
+	case "trace", "untrace":
+		return special(form, evaluateTrace)
+	case "deftest":
+		return special(form, evaluateDeftest)
+	case "testing":
+		return special(form, evaluateTesting)
+	case "is":
+		return special(form, evaluateIs)
+	case "assert=":
+		return special(form, evaluateAssertEq)
+	case "are":
+		return special(form, evaluateAre)
+	case "for-all":
+		return special(form, evaluateForAll)
+	}
+	return analyzeApplication(form.Items(), tail, scope)
+}
+
+// analyzeBody analyzes a sequence of expressions; the value of the last is the value of the sequence.
+func analyzeBody(exprs []LangType, tail bool, scope *lexicalScope) analyzed {
+	body := analyzeAll(exprs[:len(exprs)-1], scope)
+	last := analyze(exprs[len(exprs)-1], tail, scope)
+	return func(env *Env) (LangType, error) {
+		for _, expr := range body {
+			if _, err := expr(env); err != nil {
+				return nil, err
+			}
+		}
+		return last(env)
+	}
+}
+
+func analyzeIf(operands List, tail bool, scope *lexicalScope) analyzed {
+	if operands.Len() < 2 || operands.Len() > 3 {
+		return failed("Invalid form for if")
+	}
+	items := operands.Items()
+	predicate, consequent := analyze(items[0], false, scope), analyze(items[1], tail, scope)
+	var alternative analyzed
+	if len(items) == 3 {
+		alternative = analyze(items[2], tail, scope)
+	}
+	return func(env *Env) (LangType, error) {
+		value, err := predicate(env)
+		if err != nil {
+			return nil, err
+		}
+		result, isBool := value.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("If predicate must evaluate to either %t or %t", true, false)
+		}
+		if result {
+			return consequent(env)
+		}
+		if alternative != nil {
+			return alternative(env)
+		}
+		return false, nil
+	}
+}
+
+func analyzeDefine(form, operands List, scope *lexicalScope) analyzed {
+	if operands.Len() < 2 {
+		return failed("Invalid form for define")
+	}
+	symbol, isSymbol := operands.First().(Symbol)
+	if !isSymbol {
+		return failed("First argument must be a symbol")
+	}
+
+	var value analyzed
+	if operands.Len() >= 3 {
+		// syntactic sugar for a procedure definition
+		procdef := operands.Rest().(List)
+		params, isVec := procdef.First().(Vector)
+		if !isVec {
+			return failed("Second argument must be a vector for procedure definition")
+		}
+		value = analyzeLambda(params, procdef.Rest().(List), form.source, scope)
+	} else {
+		value = analyze(operands.Nth(1), false, scope)
+	}
+
+	// local definitions have a slot in the frame of the procedure
+	depth, index, isLocal := scope.resolve(symbol)
+	isSlot := isLocal && depth == 0
+	return func(env *Env) (LangType, error) {
+		defval, err := value(env)
+		if err != nil {
+			return nil, err
+		}
+		defval = named(defval, symbol)
+		if !isSlot {
+			env.Define(symbol, defval)
+		} else if _, isUnbound := env.slots[index].(unbound); isUnbound {
+			env.slots[index] = defval
+		}
+		return defval, nil
+	}
+}
+
+// slotFrames gives the parameters and local definitions of analyzed procedures slots in the frames
+// of their applications. Without it, they are defined by name in the map of the frame, which the
+// benchmarks compare with slots.
+var slotFrames = true
+
+// analyzeLambda analyzes the body of a procedure once; each Lambda made by the analyzed form shares
+// the analyzed body. The parameters and local definitions of the procedure are given slots in the
+// frame of its applications.
+func analyzeLambda(params Vector, body List, source *Source, scope *lexicalScope) analyzed {
+	lambda, err := MakeLambda(Env{}, params, body)
+	if err != nil {
+		return failed("%s", err)
+	}
+	inner := &lexicalScope{outer: scope}
+	for _, param := range params {
+		if _, isSymbol := param.(Symbol); !isSymbol {
+			return failed("Parameter %s must be a symbol", param)
+		}
+	}
+	exprs := lambda.body.Items()
+	if slotFrames {
+		for _, param := range params {
+			inner.names = append(inner.names, param.(Symbol))
+		}
+		for _, expr := range exprs {
+			inner.collectDefines(expr)
+		}
+	}
+	lambda.source = source
+	lambda.slots = inner.names
+	lambda.analyzedBody = analyzeBody(exprs, true, inner)
+	return func(env *Env) (LangType, error) {
+		closure := lambda
+		closure.env, closure.frame = *env, env
+		return closure, nil
+	}
+}
+
+func analyzeWithOpen(operands List, scope *lexicalScope) analyzed {
+	if operands.Len() < 2 {
+		return failed("Invalid form for with-open")
+	}
+	binding, isVec := operands.First().(Vector)
+	if !isVec || binding.Len() != 2 {
+		return failed("First argument to with-open must be a vector of a symbol and a port")
+	}
+	name, isSymbol := binding[0].(Symbol)
+	if !isSymbol {
+		return failed("First argument to with-open must be a vector of a symbol and a port")
+	}
+	port := analyze(binding[1], false, scope)
+	// the body is in a scope of its own, like the body of a procedure
+	exprs := operands.Rest().(List).Items()
+	inner := &lexicalScope{names: []Symbol{name}, outer: scope}
+	for _, expr := range exprs {
+		inner.collectDefines(expr)
+	}
+	body := analyzeAll(exprs, inner)
+
+	return func(env *Env) (LangType, error) {
+		value, err := port(env)
+		if err != nil {
+			return nil, err
+		}
+		closer, isCloser := value.(io.Closer)
+		if !isCloser {
+			return nil, fmt.Errorf("%s cannot be closed", value)
+		}
+
+		// the body is not evaluated in tail position; the port is closed after evaluation
+		frame := makeFrame(env, inner.names, []LangType{value})
+		var result LangType
+		for _, expr := range body {
+			if result, err = expr(&frame); err != nil {
+				break
+			}
+		}
+
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// analyzeApplication analyzes the application of a procedure. In tail position, analyzed Lambdas are
+// returned as a *tailCall to be applied by the caller.
+func analyzeApplication(items []LangType, tail bool, scope *lexicalScope) analyzed {
+	exprs := analyzeAll(items, scope)
+	return func(env *Env) (LangType, error) {
+		values, err := evaluateAnalyzed(exprs, env)
+		if err != nil {
+			return nil, err
+		}
+		procedure, args := values[0], values[1:]
+		if lambda, isLambda := procedure.(Lambda); tail && isLambda && lambda.analyzedBody != nil && !lambda.traced {
+			if len(args) != len(lambda.params) {
+				return nil, fmt.Errorf("Incorrect number of arguments to apply lambda")
+			}
+			return &tailCall{lambda, args}, nil
+		}
+		return apply(procedure, args, *env)
+	}
+}
+
+// applyAnalyzed applies an analyzed Lambda to args, and then the Lambdas its body applies in tail
+// position.
+func (lambda Lambda) applyAnalyzed(args []LangType) (LangType, error) {
+	for {
+		env := makeFrame(lambda.frame, lambda.slots, args)
+		for i := len(lambda.slots); i < len(args); i++ {
+			// the procedure was analyzed without slotFrames
+			env.Define(lambda.params[i].(Symbol), args[i])
+		}
+		result, err := lambda.analyzedBody(&env)
+		if err != nil {
+			return nil, err
+		}
+		call, isTailCall := result.(*tailCall)
+		if !isTailCall {
+			return result, nil
+		}
+		lambda, args = call.lambda, call.args
+	}
+}
+
+// collectDefines adds a slot for each symbol defined by expr in the environment of the procedure,
+// so definitions are addressed like parameters. Definitions inside quoted forms, lambdas and the
+// special forms analyzed with special are not local to the procedure.
+func (s *lexicalScope) collectDefines(expr LangType) {
+	switch t := expr.(type) {
+	case Vector:
+		for _, item := range t {
+			s.collectDefines(item)
+		}
+	case Map:
+		for _, value := range t.values {
+			s.collectDefines(value)
+		}
+	case List:
+		if t.Len() == 0 {
+			return
+		}
+		items := t.Items()
+		op, _ := items[0].(Symbol)
+		switch {
+		case op == "define" && len(items) > 1:
+			if symbol, isSymbol := items[1].(Symbol); isSymbol && !s.defines(symbol) {
+				s.names = append(s.names, symbol)
+			}
+			if len(items) == 3 {
+				s.collectDefines(items[2])
+			}
+		case op == "if" || op == "begin" || !isSpecialForm(op):
+			for _, item := range items {
+				s.collectDefines(item)
+			}
+		}
+	}
+}
+
+func (s *lexicalScope) defines(symbol Symbol) bool {
+	for _, name := range s.names {
+		if name == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// apply applies a procedure to args. Traced procedures are traced to the *err* port of env.
+func apply(procedure LangType, args []LangType, env Env) (LangType, error) {
+	switch t := procedure.(type) {
+	case Lambda:
+		if len(args) != len(t.params) {
+			return nil, fmt.Errorf("Incorrect number of arguments to apply lambda")
+		}
+		if t.traced {
+			return traceApplication(t.name, args, env, func() (LangType, error) {
+				return t.call(args)
+			})
+		}
+		return t.call(args)
+	case Subroutine:
+		if t.traced {
+			return traceApplication(t.Name, args, env, func() (LangType, error) {
+				return t.Apply(args...)
+			})
+		}
+		return t.Apply(args...)
+	}
+	return nil, fmt.Errorf("'%s' is not applicable", procedure)
+}
+
+// call applies the Lambda to args: with its analyzed body if it was analyzed, otherwise with
+// Evaluate.
+func (lambda Lambda) call(args []LangType) (LangType, error) {
+	if lambda.analyzedBody != nil {
+		return lambda.applyAnalyzed(args)
+	}
+	env := MakeEnv(&lambda.env)
+	for i, arg := range args {
+		env.Define(lambda.params[i].(Symbol), arg)
+	}
+	tail, err := evaluateBodyTCO(lambda.body, env)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(tail, env)
+}
+
+// named names an anonymous Lambda after the symbol it is defined as.
+func named(value LangType, symbol Symbol) LangType {
+	if lambda, isLambda := value.(Lambda); isLambda && lambda.name == "" {
+		lambda.name = symbol
+		return lambda
+	}
+	return value
+}
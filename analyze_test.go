@@ -0,0 +1,185 @@
+package slang_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// arithmeticEnv makes an environment with the subroutines used by the programs of the
+// evaluator tests and benchmarks.
+func arithmeticEnv() slang.Env {
+	env := slang.MakeEnv(nil)
+	numbers := func(name string, f func(a, b slang.Number) slang.LangType) {
+		env.Define(slang.Symbol(name), slang.Subroutine{Name: slang.Symbol(name),
+			Func: func(args ...slang.LangType) (slang.LangType, error) {
+				a, isNumber := args[0].(slang.Number)
+				b, isOtherNumber := args[1].(slang.Number)
+				if len(args) != 2 || !isNumber || !isOtherNumber {
+					return nil, fmt.Errorf("Expected 2 numbers")
+				}
+				return f(a, b), nil
+			},
+		})
+	}
+	numbers("+", func(a, b slang.Number) slang.LangType { return a + b })
+	numbers("-", func(a, b slang.Number) slang.LangType { return a - b })
+	numbers("*", func(a, b slang.Number) slang.LangType { return a * b })
+	numbers("<", func(a, b slang.Number) slang.LangType { return a < b })
+	env.Define(slang.Symbol("="), slang.Subroutine{Name: "=",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return slang.Eq(args[0], args[1]), nil },
+	})
+	env.Define(slang.Symbol("nth"), slang.Subroutine{Name: "nth",
+		Func: func(args ...slang.LangType) (slang.LangType, error) {
+			return args[0].(slang.Sequence).Nth(args[1].(slang.Number)), nil
+		},
+	})
+	env.Define(slang.Symbol("len"), slang.Subroutine{Name: "len",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return args[0].(slang.Sequence).Len(), nil },
+	})
+	return env
+}
+
+var programs = []string{
+	"(define fib [n] (if (< n 2) n (+ (fib (- n 1)) (fib (- n 2))))) (fib 15)",
+	"(define loop [i acc] (if (= i 0) acc (loop (- i 1) (+ acc i)))) (loop 100000 0)",
+	"(define deep [n] (if (= n 0) 0 (+ 1 (deep (- n 1))))) (deep 2000)",
+	"(define adder [x] (lambda [y] (+ x y))) ((adder 1) 2)",
+	"(define counter [n] (define step [i] (if (< i n) (step (+ i 1)) i)) (step 0)) (counter 5)",
+	"(define f [x] (define y (* x 2)) (define y 0) [x y {\"sum\" (+ x y)}]) (f 3)",
+	"(define f [x] \"Doubles x.\" (* x 2)) (f 4)",
+	"(define g (lambda [] 1)) g",
+	"(begin 1 2 3)",
+	"(if false 1)",
+	"'(a b)",
+	"()",
+	"(define x 1) (define x 2) x",
+	"(define f [x] x) (f 1 2)",
+	"(1 2)",
+	"((lambda [x] x) 1 2)",
+	"(if 1 2 3)",
+	"(undefined 1)",
+	"(define f [] (g)) (define g [] (+ 1 missing)) (f)",
+	"(define f [] (define y (+ 1 y)) y) (f)",
+	"(define f [x] (is (= x 1))) (f 1)",
+	"(define f [x] (with-open [p x] p)) (f 1)",
+	"(define f [] (quote 1 2)) (f)",
+	"(define f [x] (lambda [] (if x 1 2))) ((f 1))",
+	"(define x 10) (define f [] (define x (+ x 1)) x) (f)",
+	"(define f [] (if false (define x 1)) x) (define x 5) (f)",
+	"(define even? [n] (if (= n 0) true (odd? (- n 1)))) (define odd? [n] (if (= n 0) false (even? (- n 1)))) (even? 10001)",
+	"(define make [x] (define get [] x) get) ((make 3))",
+	"(define f [] \"Doc.\" (begin 1 2)) (f)",
+	"(define f [x] (lambda [] x)) (define g (f 1)) [(g) {\"k\" (g)}]",
+	"(define f [n] (define g [m] (if (= m 0) n (g (- m 1)))) (g n)) (f 10)",
+	"(if (< 1 2) (quote a))",
+	"(\"s\" 1)",
+	"(define)",
+	"(define y 1) (define f [] (define z y) (define y 2) [z y]) (f)",
+	"(define f [x x] x) (f 1 2)",
+	"(define f [x] (testing \"t\" (define w x)) [w x]) (f 1)",
+	"(define f [x] (with-open [p x] (define q p) q)) (f 1)",
+	"(define f [x] (define g [] (define x 2) x) [(g) x]) (f 1)",
+	"(define f [x] (define x 3) x) (f 1)",
+}
+
+// run evaluates the forms of a program with evaluate in a new environment and returns the value of
+// the last form, or the error.
+func run(t *testing.T, program string, evaluate func(slang.LangType, slang.Env) (slang.LangType, error)) string {
+	t.Helper()
+	exprs, err := parser.Parse(t.Name(), program)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned unexpected error %s", program, err)
+	}
+	env := arithmeticEnv()
+	var result slang.LangType
+	for _, expr := range exprs {
+		if result, err = evaluate(expr, env); err != nil {
+			return "error: " + err.Error()
+		}
+	}
+	return fmt.Sprint(result)
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, program := range programs {
+		got, want := run(t, program, slang.Evaluate), run(t, program, slang.Walk)
+		if got != want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", program, got, want)
+		}
+	}
+}
+
+func TestMapFrames(t *testing.T) {
+	for _, program := range programs {
+		got, want := run(t, program, slang.MapFrames), run(t, program, slang.Evaluate)
+		if got != want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", program, got, want)
+		}
+	}
+}
+
+func TestAnalysisRun(t *testing.T) {
+	exprs, _ := parser.Parse("TestAnalysisRun", "(define f [x] (+ x n))")
+	analysis := slang.Analyze(exprs[0])
+	call, _ := parser.Parse("TestAnalysisRun", "(f 1)")
+	for _, n := range []slang.Number{1, 2} {
+		env := arithmeticEnv()
+		env.Define(slang.Symbol("n"), n)
+		if _, err := analysis.Run(env); err != nil {
+			t.Fatalf("Run returned unexpected error %s", err)
+		}
+		if got, err := slang.Evaluate(call[0], env); err != nil || got != n+1 {
+			t.Errorf("\n%s:\n\tgot %v %v\n\texp %v", "TestAnalysisRun", got, err, n+1)
+		}
+	}
+}
+
+var benchmarks = []struct {
+	name    string
+	define  string
+	program string
+}{
+	{"fib", "(define fib [n] (if (< n 2) n (+ (fib (- n 1)) (fib (- n 2)))))", "(fib 15)"},
+	{"loop", "(define loop [i acc] (if (= i 0) acc (loop (- i 1) (+ acc i))))", "(loop 10000 0)"},
+	{"sequence", "(define sum [v i acc] (if (= i (len v)) acc (sum v (+ i 1) (+ acc (nth v i)))))",
+		"(sum [1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20] 0 0)"},
+	{"closure", "(define count [n] (define step [i acc] (if (= i 0) acc (step (- i 1) (+ acc n)))) (step n 0))",
+		"(count 5000)"},
+}
+
+// benchmarkEvaluators runs the benchmark programs with each of the evaluators.
+func benchmarkEvaluators(b *testing.B, evaluators []evaluator) {
+	for _, bench := range benchmarks {
+		for _, evaluator := range evaluators {
+			b.Run(bench.name+"/"+evaluator.name, func(b *testing.B) {
+				env := arithmeticEnv()
+				define, _ := parser.Parse("define", bench.define)
+				program, _ := parser.Parse("program", bench.program)
+				evaluator.evaluate(define[0], env)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := evaluator.evaluate(program[0], env); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+type evaluator struct {
+	name     string
+	evaluate func(slang.LangType, slang.Env) (slang.LangType, error)
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	benchmarkEvaluators(b, []evaluator{{"Walk", slang.Walk}, {"Evaluate", slang.Evaluate}})
+}
+
+// BenchmarkFrames compares analyzed evaluation with frames of slots and with frames of maps.
+func BenchmarkFrames(b *testing.B) {
+	benchmarkEvaluators(b, []evaluator{{"map", slang.MapFrames}, {"slots", slang.Evaluate}})
+}
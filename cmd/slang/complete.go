@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/zachorosz/slang"
+)
+
+// wordSeparators delimit the word being completed in the REPL.
+const wordSeparators = "()[] '\""
+
+// describe returns a short completion description of a value. Procedures are distinguished from
+// values, and Lambdas list their parameters.
+func describe(value slang.LangType) string {
+	switch t := value.(type) {
+	case slang.Lambda:
+		return fmt.Sprintf("procedure %s", t.Params())
+	case slang.Subroutine:
+		return "procedure"
+	default:
+		return "value"
+	}
+}
+
+// suggestions enumerates completion candidates from every frame of env, the special forms and the
+// loaded subroutine packages. Symbols shadowed by an inner frame are only suggested once.
+func suggestions(env *slang.Env) []prompt.Suggest {
+	s := []prompt.Suggest{}
+	seen := map[slang.Symbol]bool{}
+
+	for _, form := range slang.SpecialForms {
+		s = append(s, prompt.Suggest{Text: string(form), Description: "special form"})
+		seen[form] = true
+	}
+
+	for frame := env; frame != nil; frame = frame.Outer() {
+		pkgOf := map[slang.Symbol]string{}
+		for pkgName, symbols := range frame.Packages() {
+			for _, symbol := range symbols {
+				pkgOf[symbol] = pkgName
+			}
+		}
+
+		for _, symbol := range frame.Symbols() {
+			if seen[symbol] {
+				continue
+			}
+			seen[symbol] = true
+
+			value, _ := frame.Get(symbol)
+			description := describe(value)
+			if pkgName, ok := pkgOf[symbol]; ok && pkgName != "" {
+				description = fmt.Sprintf("%s (%s)", description, pkgName)
+			}
+			s = append(s, prompt.Suggest{Text: string(symbol), Description: description})
+		}
+	}
+
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Text < s[j].Text })
+	return s
+}
+
+func completer(d prompt.Document) []prompt.Suggest {
+	word := d.GetWordBeforeCursorUntilSeparator(wordSeparators)
+	if word == "" {
+		return []prompt.Suggest{}
+	}
+	return prompt.FilterHasPrefix(suggestions(&env), word, true)
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+	"github.com/zachorosz/slang/printer"
+)
+
+const debugPrompt = "(debug) "
+
+// debugHelp lists the debugger commands in the order they are shown by `help`.
+var debugHelp = [][2]string{
+	{"break line|name", "pause before the form starting line, or when the procedure name is applied"},
+	{"clear line|name", "remove a breakpoint"},
+	{"breakpoints", "list the breakpoints"},
+	{"continue", "run until the next breakpoint"},
+	{"step", "pause before the next form, entering procedures"},
+	{"next", "pause before the next form of the current procedure"},
+	{"locals", "print the bindings of the enclosing environments"},
+	{"print expr", "evaluate expr in the paused environment and print the result"},
+	{"backtrace", "print the call stack"},
+	{"help", "show this message"},
+	{"quit", "stop the program"},
+}
+
+// debugAliases are short names of debugger commands.
+var debugAliases = map[string]string{
+	"b": "break", "c": "continue", "s": "step", "n": "next", "l": "locals", "p": "print",
+	"bt": "backtrace", "h": "help", "q": "quit",
+}
+
+// errDebugQuit stops a program when the user quits the debugger.
+var errDebugQuit = errors.New("Quit")
+
+// debugger is a slang.Hook that pauses evaluation at breakpoints and reads commands.
+type debugger struct {
+	filename string
+	lines    []string    // source lines of the debugged file
+	starts   map[int]int // line to column of the first list starting on the line
+	in       *bufio.Scanner
+	out      io.Writer
+
+	breakLines map[int]bool
+	breakNames map[slang.Symbol]bool
+	step       bool // pause before the next form
+	next       int  // pause before the next form at or above this stack depth; -1 if not set
+	last       string
+	evaluating bool // evaluating a print command; hooks are ignored
+}
+
+// newDebugger constructs a debugger for the source of filename. It fails if the source cannot be
+// parsed.
+func newDebugger(filename, src string, in io.Reader, out io.Writer) (*debugger, error) {
+	root, err := parser.ParseCST(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d := &debugger{
+		filename:   filename,
+		lines:      strings.Split(src, "\n"),
+		starts:     map[int]int{},
+		in:         bufio.NewScanner(in),
+		out:        out,
+		breakLines: map[int]bool{},
+		breakNames: map[slang.Symbol]bool{},
+		next:       -1,
+	}
+	var walk func(n *parser.Node)
+	walk = func(n *parser.Node) {
+		if n.Kind == parser.NodeList {
+			if column, seen := d.starts[n.Start.Line]; !seen || n.Start.Column < column {
+				d.starts[n.Start.Line] = n.Start.Column
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return d, nil
+}
+
+// Eval pauses before lists read from source when stepping or at a line breakpoint.
+func (d *debugger) Eval(expr slang.LangType, env slang.Env, stack []slang.Frame) error {
+	lst, isList := expr.(slang.List)
+	if d.evaluating || !isList {
+		return nil
+	}
+	src, hasSource := lst.Source()
+	if !hasSource {
+		return nil
+	}
+
+	switch {
+	case d.step, d.next >= 0 && len(stack) <= d.next:
+		return d.pause(src, env, stack)
+	case src.File == d.filename && d.breakLines[src.Line] && d.starts[src.Line] == src.Column:
+		fmt.Fprintf(d.out, "Breakpoint at line %d\n", src.Line)
+		return d.pause(src, env, stack)
+	}
+	return nil
+}
+
+// Apply pauses when a procedure with a breakpoint is applied.
+func (d *debugger) Apply(stack []slang.Frame) error {
+	frame := stack[len(stack)-1]
+	if d.evaluating || !d.breakNames[frame.Name()] {
+		return nil
+	}
+	fmt.Fprintf(d.out, "Breakpoint at %s\n", frame.Name())
+	src, _ := frame.Form.Source()
+	return d.pause(src, frame.Env, stack)
+}
+
+// pause shows where evaluation is paused and reads commands until one resumes evaluation.
+func (d *debugger) pause(src slang.Source, env slang.Env, stack []slang.Frame) error {
+	d.step, d.next = false, -1
+	d.where(src)
+	for {
+		fmt.Fprint(d.out, debugPrompt)
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			return errDebugQuit
+		}
+		line := strings.TrimSpace(d.in.Text())
+		if line == "" {
+			line = d.last // repeat the last command, like step
+		}
+		d.last = line
+
+		command, arg := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			command, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		if name, isAlias := debugAliases[command]; isAlias {
+			command = name
+		}
+
+		switch command {
+		case "":
+		case "continue":
+			return nil
+		case "step":
+			d.step = true
+			return nil
+		case "next":
+			d.next = len(stack)
+			return nil
+		case "quit":
+			return errDebugQuit
+		case "locals":
+			d.locals(env)
+		case "print":
+			d.print(arg, env)
+		case "backtrace":
+			d.backtrace(src, stack)
+		default:
+			d.command(command, arg)
+		}
+	}
+}
+
+// command runs the commands that can be used before the program starts.
+func (d *debugger) command(command, arg string) {
+	switch command {
+	case "break", "clear":
+		line, err := strconv.Atoi(arg)
+		switch {
+		case arg == "":
+			fmt.Fprintf(d.out, "Usage: %s line|name\n", command)
+		case err == nil && d.starts[line] == 0:
+			fmt.Fprintf(d.out, "No form starts on line %d\n", line)
+		case err == nil:
+			d.breakLines[line] = command == "break"
+		default:
+			d.breakNames[slang.Symbol(arg)] = command == "break"
+		}
+	case "breakpoints":
+		var breakpoints []string
+		for line, set := range d.breakLines {
+			if set {
+				breakpoints = append(breakpoints, fmt.Sprintf("%s:%d", d.filename, line))
+			}
+		}
+		for name, set := range d.breakNames {
+			if set {
+				breakpoints = append(breakpoints, string(name))
+			}
+		}
+		sort.Strings(breakpoints)
+		for _, breakpoint := range breakpoints {
+			fmt.Fprintln(d.out, breakpoint)
+		}
+	case "help":
+		for _, help := range debugHelp {
+			fmt.Fprintf(d.out, "  %-18s %s\n", help[0], help[1])
+		}
+	default:
+		fmt.Fprintf(d.out, "Unknown command %s; type help for a list of commands\n", command)
+	}
+}
+
+// where prints the location of a paused form with its source line.
+func (d *debugger) where(src slang.Source) {
+	fmt.Fprintf(d.out, "%s\n", src)
+	if src.File == d.filename && src.Line <= len(d.lines) {
+		fmt.Fprintf(d.out, "%d | %s\n", src.Line, strings.TrimRight(d.lines[src.Line-1], "\r"))
+	}
+}
+
+// locals prints the bindings of each environment enclosing env, innermost first. The global
+// environment is not printed.
+func (d *debugger) locals(env slang.Env) {
+	for frame := &env; frame.Outer() != nil; frame = frame.Outer() {
+		for _, symbol := range frame.Symbols() {
+			value, _ := frame.Get(symbol)
+			fmt.Fprintf(d.out, "%s = %s\n", symbol, printer.PrStr(value))
+		}
+	}
+}
+
+// print evaluates the forms of expr in env and prints their results.
+func (d *debugger) print(expr string, env slang.Env) {
+	forms, err := parser.Parse("debug", expr)
+	if err != nil {
+		printError(d.out, err)
+		return
+	}
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+	for _, form := range forms {
+		result, err := slang.Evaluate(form, env)
+		if err != nil {
+			fmt.Fprintln(d.out, err)
+			return
+		}
+		printer.Fprintln(d.out, result, printer.Readable)
+	}
+}
+
+// backtrace prints the call stack, innermost first, with the location of each application.
+func (d *debugger) backtrace(src slang.Source, stack []slang.Frame) {
+	fmt.Fprintf(d.out, "#0 %s\n", src)
+	for i := len(stack) - 1; i >= 0; i-- {
+		frame := stack[i]
+		call := slang.MakeList(frame.Name())
+		for _, arg := range frame.Args {
+			call = call.Append(arg).(slang.List)
+		}
+		location := "?"
+		if src, hasSource := frame.Form.Source(); hasSource {
+			location = src.String()
+		}
+		fmt.Fprintf(d.out, "#%d %s at %s\n", len(stack)-i, printer.PrStr(call), location)
+	}
+}
+
+// runDebug evaluates a slang file under the debugger, reading commands from stdin. The program
+// shares stdin and stdout with the debugger. It returns the exit status: 1 if the file could not be
+// read or evaluation failed.
+// Usage: `slang debug file [arguments]`
+func runDebug(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slang debug file [arguments]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	d, err := newDebugger(filename, string(src), stdin, stdout)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	env := slang.MakeEnv(nil)
+	programArgs := flags.Args()[1:]
+	setupEnvPorts(&env, len(programArgs), programArgs, stdin, stdout, stderr)
+
+	// commands before the program starts set breakpoints; continue or step starts it
+	fmt.Fprintf(stdout, "Debugging %s. Type help for a list of commands.\n", filename)
+	for {
+		fmt.Fprint(stdout, debugPrompt)
+		if !d.in.Scan() {
+			fmt.Fprintln(stdout)
+			return 0
+		}
+		command := strings.Fields(d.in.Text())
+		if len(command) == 0 {
+			continue
+		}
+		name := command[0]
+		if alias, isAlias := debugAliases[name]; isAlias {
+			name = alias
+		}
+		if name == "quit" {
+			return 0
+		}
+		if name == "continue" || name == "step" || name == "next" {
+			d.step, d.last = name != "continue", d.in.Text()
+			break
+		}
+		d.command(name, strings.TrimSpace(strings.TrimPrefix(d.in.Text(), command[0])))
+	}
+
+	env.SetHook(d)
+	err = evaluateFile(env, filename, nil)
+	env.SetHook(nil)
+	if err == errDebugQuit {
+		return 0
+	} else if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, "Program finished")
+	return 0
+}
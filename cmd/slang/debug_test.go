@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDebug(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang-debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "sum.sl")
+	src := "(define sum [n acc]\n  (if (= n 0)\n    acc\n    (sum (- n 1) (+ acc n))))\n(println (sum 2 0))\n"
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		commands string
+		want     []string
+	}{
+		{"breakpoint", "break 4\nc\nlocals\np (+ n acc)\nclear 4\nc\n",
+			[]string{"Breakpoint at line 4", "4 |     (sum (- n 1) (+ acc n))))", "acc = 0\nn = 2", "(debug) 2\n", "3\nProgram finished"}},
+		{"procedure", "b sum\nc\nbt\nq\n",
+			[]string{"Breakpoint at sum", "#1 (sum 2 0) at " + filename + ":5:10"}},
+		{"step", "s\n\ns\ns\nq\n", []string{filename + ":1:1", filename + ":5:1", filename + ":5:10", filename + ":2:3"}},
+		{"next", "s\n\n\nn\n", []string{filename + ":5:10", "3\nProgram finished"}},
+		{"unknown", "frobnicate\nbreak 3\nq\n", []string{"Unknown command frobnicate", "No form starts on line 3"}},
+	}
+
+	for _, c := range cases {
+		var stdout, stderr bytes.Buffer
+		status := runDebug([]string{filename}, strings.NewReader(c.commands), &stdout, &stderr)
+		if status != 0 {
+			t.Errorf("\n%s:\n\tgot status %d %q\n\texp 0", c.name, status, stderr.String())
+		}
+		for _, want := range c.want {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("\n%s:\n\tgot %q\n\texp %q", c.name, stdout.String(), want)
+			}
+		}
+	}
+}
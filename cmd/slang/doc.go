@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zachorosz/slang"
+)
+
+// params makes a parameter Vector for a SubrDoc.
+func params(names ...string) slang.Vector {
+	vec := make(slang.Vector, len(names))
+	for i, name := range names {
+		vec[i] = slang.Symbol(name)
+	}
+	return vec
+}
+
+// DocPrimitives returns the documentation primitives. apropos searches the symbols of env.
+func DocPrimitives(env *slang.Env) map[string]func(...slang.LangType) (slang.LangType, error) {
+	return map[string]func(...slang.LangType) (slang.LangType, error){
+		"doc": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			return slang.Doc(args[0])
+		},
+		"arglist": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			return slang.Arglist(args[0])
+		},
+		"apropos": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			substr, isStr := args[0].(slang.Str)
+			if !isStr {
+				return nil, fmt.Errorf("%s is not a string", args[0])
+			}
+			return slang.Apropos(env, string(substr)), nil
+		},
+	}
+}
+
+// DocPrimitiveDocs documents DocPrimitives.
+var DocPrimitiveDocs = map[string]slang.SubrDoc{
+	"doc":     {Params: params("procedure"), Doc: "Returns the documentation of a procedure."},
+	"arglist": {Params: params("procedure"), Doc: "Returns the parameter vector of a procedure."},
+	"apropos": {Params: params("substr"), Doc: "Returns a vector of defined symbols whose names contain substr."},
+}
+
+// PrimitiveDocs documents Primitives.
+var PrimitiveDocs = map[string]slang.SubrDoc{
+	"char?":      {Params: params("x"), Doc: "Returns true if x is a character."},
+	"inst?":      {Params: params("x"), Doc: "Returns true if x is an instant."},
+	"uuid?":      {Params: params("x"), Doc: "Returns true if x is a UUID."},
+	"list?":      {Params: params("x"), Doc: "Returns true if x is a list."},
+	"nil?":       {Params: params("x"), Doc: "Returns true if x is nil or an empty list."},
+	"number?":    {Params: params("x"), Doc: "Returns true if x is a number."},
+	"procedure?": {Params: params("x"), Doc: "Returns true if x is a lambda or subroutine."},
+	"seq?":       {Params: params("x"), Doc: "Returns true if x is a sequence."},
+	"string?":    {Params: params("x"), Doc: "Returns true if x is a string."},
+	"symbol?":    {Params: params("x"), Doc: "Returns true if x is a symbol."},
+	"vec?":       {Params: params("x"), Doc: "Returns true if x is a vector."},
+	">":          {Params: params("x", "y"), Doc: "Returns true if x is greater than y."},
+	"<":          {Params: params("x", "y"), Doc: "Returns true if x is less than y."},
+	">=":         {Params: params("x", "y"), Doc: "Returns true if x is greater than or equal to y."},
+	"<=":         {Params: params("x", "y"), Doc: "Returns true if x is less than or equal to y."},
+	"=":          {Params: params("x", "y"), Doc: "Returns true if x is equal to y. Sequences are compared item by item."},
+	"+":          {Params: params("x", "y"), Doc: "Returns the sum of two numbers or the concatenation of a string."},
+	"-":          {Params: params("x", "y"), Doc: "Returns the difference of two numbers."},
+	"*":          {Params: params("x", "y"), Doc: "Returns the product of two numbers or a string repeated y times."},
+	"/":          {Params: params("x", "y"), Doc: "Returns the quotient of two numbers."},
+	"%":          {Params: params("x", "y"), Doc: "Returns the remainder of the quotient of two numbers."},
+	"append":     {Params: params("seq", "item"), Doc: "Returns a copy of seq with item appended."},
+	"first":      {Params: params("seq"), Doc: "Returns the first item of seq."},
+	"rest":       {Params: params("seq"), Doc: "Returns seq without its first item."},
+	"nth":        {Params: params("seq", "n"), Doc: "Returns the nth (zero-based) item of seq."},
+	"len":        {Params: params("seq"), Doc: "Returns the length of seq."},
+	"list":       {Params: params("item", "&", "items"), Doc: "Returns a new list of the given items."},
+	"vec":        {Params: params("item", "&", "items"), Doc: "Returns a new vector of the given items."},
+	"pr-str":     {Params: params("x"), Doc: "Returns the readable representation of x that can be read back by the parser."},
+	"print-str":  {Params: params("x"), Doc: "Returns the human readable representation of x. Strings are not quoted."},
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/zachorosz/slang/format"
+)
+
+// fmtOptions are the flags of the fmt command.
+type fmtOptions struct {
+	write  bool // rewrite files in place
+	diff   bool // print diffs instead of formatted source
+	config format.Config
+}
+
+// runFmt formats slang source files, or standard input if no files are named. It returns the exit
+// status: 1 if a file could not be formatted, or if -d is set and a file is not formatted.
+// Usage: `slang fmt [-w] [-d] [-width n] [files...]`
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slang fmt [-w] [-d] [-width n] [files...]")
+		flags.PrintDefaults()
+	}
+	var opts fmtOptions
+	flags.BoolVar(&opts.write, "w", false, "Write the result to the source file instead of standard output")
+	flags.BoolVar(&opts.diff, "d", false, "Print diffs of files that are not formatted and exit with status 1")
+	flags.IntVar(&opts.config.Width, "width", format.DefaultWidth, "Preferred maximum line width")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if opts.write {
+			fmt.Fprintln(stderr, "Cannot use -w with standard input")
+			return 2
+		}
+		src, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return opts.formatSource("<stdin>", string(src), stdout, stderr)
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+			continue
+		}
+		if s := opts.formatSource(filename, string(src), stdout, stderr); s > status {
+			status = s
+		}
+	}
+	return status
+}
+
+// formatSource formats the source of one file and writes the result, diff or file as selected by
+// the options. It returns the exit status of the file.
+func (opts fmtOptions) formatSource(filename, src string, stdout, stderr io.Writer) int {
+	formatted, err := opts.config.Source(filename, src)
+	if err != nil {
+		printError(stderr, err)
+		return 1
+	}
+
+	status := 0
+	if opts.diff {
+		if diff := unifiedDiff(filename+".orig", filename, src, formatted); diff != "" {
+			fmt.Fprint(stdout, diff)
+			status = 1
+		}
+	}
+	if opts.write {
+		if formatted != src {
+			info, err := os.Stat(filename)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+			if err := ioutil.WriteFile(filename, []byte(formatted), info.Mode().Perm()); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+		}
+	} else if !opts.diff {
+		fmt.Fprint(stdout, formatted)
+	}
+	return status
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmtStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	status := runFmt(nil, strings.NewReader("(define square [x]   (* x x))"), &stdout, &stderr)
+	want := "(define square [x]\n  (* x x))\n"
+	if status != 0 || stdout.String() != want {
+		t.Errorf("\n%s:\n\tgot %d %q %q\n\texp 0 %q", "TestFmtStdin", status, stdout.String(), stderr.String(), want)
+	}
+}
+
+func TestFmtFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "square.sl")
+	ioutil.WriteFile(filename, []byte("; squares\n(define square [x] (* x x))\n(square 2)\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if status := runFmt([]string{"-d", filename}, nil, &stdout, &stderr); status != 1 {
+		t.Errorf("\n%s:\n\tgot status %d\n\texp 1", "-d", status)
+	}
+	wantDiff := "--- " + filename + ".orig\n+++ " + filename + "\n@@ -1,3 +1,4 @@\n" +
+		" ; squares\n-(define square [x] (* x x))\n+(define square [x]\n+  (* x x))\n (square 2)\n"
+	if stdout.String() != wantDiff {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "-d", stdout.String(), wantDiff)
+	}
+
+	stdout.Reset()
+	if status := runFmt([]string{"-w", filename}, nil, &stdout, &stderr); status != 0 || stdout.Len() != 0 {
+		t.Errorf("\n%s:\n\tgot status %d and output %q\n\texp 0 and no output", "-w", status, stdout.String())
+	}
+	b, _ := ioutil.ReadFile(filename)
+	if want := "; squares\n(define square [x]\n  (* x x))\n(square 2)\n"; string(b) != want {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "-w", string(b), want)
+	}
+
+	if status := runFmt([]string{"-d", filename}, nil, &stdout, &stderr); status != 0 || stdout.Len() != 0 {
+		t.Errorf("\n%s:\n\tgot status %d and output %q\n\texp 0 and no output", "-d formatted", status, stdout.String())
+	}
+}
+
+func TestFmtSyntaxError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	status := runFmt(nil, strings.NewReader("(+ 1 ]"), &stdout, &stderr)
+	want := "<stdin>:1:6: Unexpected ']'\n"
+	if status != 1 || !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("\n%s:\n\tgot %d %q\n\texp 1 %q", "TestFmtSyntaxError", status, stderr.String(), want)
+	}
+}
+
+var diffTests = []struct {
+	a, b string
+	want string
+}{
+	{"a\nb\n", "a\nb\n", ""},
+	{"a\nb\nc\n", "a\nc\n", "@@ -1,3 +1,2 @@\n a\n-b\n c\n"},
+	{"", "a\n", "@@ -0,0 +1,1 @@\n+a\n"},
+	{
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		"0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\nx\n",
+		"@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+x\n",
+	},
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, test := range diffTests {
+		got := unifiedDiff("a", "b", test.a, test.b)
+		want := test.want
+		if want != "" {
+			want = "--- a\n+++ b\n" + want
+		}
+		if got != want {
+			t.Errorf("\n%q -> %q:\n\tgot %q\n\texp %q", test.a, test.b, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/gen"
+)
+
+// genArgs asserts that args are generators.
+func genArgs(args []slang.LangType) ([]gen.Gen, error) {
+	gens := make([]gen.Gen, len(args))
+	for i, arg := range args {
+		g, isGen := arg.(gen.Gen)
+		if !isGen {
+			return nil, fmt.Errorf("%s is not a generator", arg)
+		}
+		gens[i] = g
+	}
+	return gens, nil
+}
+
+// generator returns a primitive without arguments that returns g.
+func generator(g func() gen.Gen) func(...slang.LangType) (slang.LangType, error) {
+	return func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 0 arguments")
+		}
+		return g(), nil
+	}
+}
+
+// collectionGenerator returns a primitive that returns a generator of collections of items generated
+// by its argument.
+func collectionGenerator(of func(gen.Gen) gen.Gen) func(...slang.LangType) (slang.LangType, error) {
+	return func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		gens, err := genArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return of(gens[0]), nil
+	}
+}
+
+// GenPrimitives is a map with applications of the slang generators used by for-all.
+var GenPrimitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"gen/number":    generator(gen.Number),
+	"gen/integer":   generator(gen.Integer),
+	"gen/boolean":   generator(gen.Bool),
+	"gen/char":      generator(gen.Char),
+	"gen/string":    generator(gen.Str),
+	"gen/symbol":    generator(gen.Symbol),
+	"gen/any":       generator(gen.Any),
+	"gen/list-of":   collectionGenerator(gen.ListOf),
+	"gen/vector-of": collectionGenerator(gen.VectorOf),
+	"gen/map-of": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		gens, err := genArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return gen.MapOf(gens[0], gens[1]), nil
+	},
+	"gen/elements": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+		}
+		return gen.Elements(args...), nil
+	},
+	"gen/one-of": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+		}
+		gens, err := genArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return gen.OneOf(gens...), nil
+	},
+	"gen/sample": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 or 2 arguments")
+		}
+		gens, err := genArgs(args[:1])
+		if err != nil {
+			return nil, err
+		}
+		n := 10
+		if len(args) == 2 {
+			count, isNumber := args[1].(slang.Number)
+			if !isNumber || count < 0 {
+				return nil, fmt.Errorf("%s is not a count", args[1])
+			}
+			n = int(count)
+		}
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		sample := make(slang.Vector, n)
+		for i := range sample {
+			sample[i] = gens[0].Generate(r, i)
+		}
+		return sample, nil
+	},
+}
+
+// GenPrimitiveDocs documents GenPrimitives.
+var GenPrimitiveDocs = map[string]slang.SubrDoc{
+	"gen/number":    {Params: params(), Doc: "Returns a generator of integers and fractions."},
+	"gen/integer":   {Params: params(), Doc: "Returns a generator of integers."},
+	"gen/boolean":   {Params: params(), Doc: "Returns a generator of true and false."},
+	"gen/char":      {Params: params(), Doc: "Returns a generator of characters."},
+	"gen/string":    {Params: params(), Doc: "Returns a generator of strings, including characters that are escaped when printed."},
+	"gen/symbol":    {Params: params(), Doc: "Returns a generator of symbols."},
+	"gen/any":       {Params: params(), Doc: "Returns a generator of values of every readable type, including nested lists, vectors and maps."},
+	"gen/list-of":   {Params: params("g"), Doc: "Returns a generator of lists of values generated by g."},
+	"gen/vector-of": {Params: params("g"), Doc: "Returns a generator of vectors of values generated by g."},
+	"gen/map-of":    {Params: params("keys", "vals"), Doc: "Returns a generator of maps with keys generated by keys and values generated by vals."},
+	"gen/elements":  {Params: params("x", "&", "xs"), Doc: "Returns a generator of x and xs. Values shrink to the values before them."},
+	"gen/one-of":    {Params: params("gen", "&", "gens"), Doc: "Returns a generator of values of gen and gens."},
+	"gen/sample":    {Params: params("g", "&", "n"), Doc: "Returns a vector of n values generated by g, 10 by default, of increasing size."},
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+	"github.com/zachorosz/slang/printer"
+)
+
+var genTests = []struct {
+	program string
+	want    string
+}{
+	{`(print (for-all [x (gen/integer) y (gen/integer)] (= (+ x y) (+ y x))))`, "true"},
+	{`(print (for-all [s (gen/string)] (= s (read-string (pr-str s)))))`, "true"},
+	{`(print (gen/sample (gen/elements 7) 3))`, "[7 7 7]"},
+	{`(print (len (gen/sample (gen/map-of (gen/symbol) (gen/any)))))`, "10"},
+	{`(print (gen/sample (gen/vector-of (gen/one-of (gen/boolean) (gen/char))) 1))`, "[[]]"},
+}
+
+func TestGenPrimitives(t *testing.T) {
+	for _, test := range genTests {
+		if got := runProgram(t, test.program, ""); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+	}
+}
+
+var forAllShrinkTests = []struct {
+	program string
+	want    string
+}{
+	{"(for-all [n (gen/integer)] (< n 10))", "[n 10]"},
+	{"(for-all [xs (gen/list-of (gen/integer))] (< (len xs) 3))", "[xs (0 0 0)]"},
+	{"(for-all [s (gen/string) b (gen/boolean)] (if b (= s \"\") true))", "[s \"a\" b true]"},
+}
+
+func TestForAllShrinks(t *testing.T) {
+	for _, test := range forAllShrinkTests {
+		env := slang.MakeEnv(nil)
+		testSetup(strings.NewReader(""), &bytes.Buffer{})(&env)
+		exprs, err := parser.Parse("TestForAllShrinks", test.program)
+		if err != nil {
+			t.Fatalf("Parse returned unexpected error %s", err)
+		}
+		_, err = slang.Evaluate(exprs[0], env)
+		failure, isFailure := err.(*slang.AssertionError)
+		if !isFailure {
+			t.Errorf("\n%s:\n\tgot %v\n\texp an assertion error", test.program, err)
+			continue
+		}
+		if got := printer.PrStr(failure.Actual); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+		if !strings.HasPrefix(failure.Message, "Falsified after ") {
+			t.Errorf("\n%s:\n\tgot %q\n\texp a falsified message", test.program, failure.Message)
+		}
+	}
+}
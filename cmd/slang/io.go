@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/printer"
+)
+
+// port returns the Port bound to symbol in env.
+func port(env *slang.Env, symbol slang.Symbol) (*slang.Port, error) {
+	value, err := env.Get(symbol)
+	if err != nil {
+		return nil, err
+	}
+	p, isPort := value.(*slang.Port)
+	if !isPort {
+		return nil, fmt.Errorf("%s is not a port", symbol)
+	}
+	return p, nil
+}
+
+// displayArgs returns the display representations of args separated by spaces.
+func displayArgs(args []slang.LangType) string {
+	items := make([]string, len(args))
+	for i, arg := range args {
+		items[i] = printer.DisplayStr(arg)
+	}
+	return strings.Join(items, " ")
+}
+
+// optionalBool returns the boolean argument at index i, or false if it was not passed.
+func optionalBool(args []slang.LangType, i int) (bool, error) {
+	if len(args) <= i {
+		return false, nil
+	}
+	b, isBool := args[i].(bool)
+	if !isBool {
+		return false, fmt.Errorf("%s is not a boolean", args[i])
+	}
+	return b, nil
+}
+
+// IOPrimitives returns the I/O primitives. Printing and reading without an explicit port use the
+// ports bound to *out* and *in* in env when called.
+func IOPrimitives(env *slang.Env) map[string]func(...slang.LangType) (slang.LangType, error) {
+	return map[string]func(...slang.LangType) (slang.LangType, error){
+		"port?": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			return slang.PortP(args[0]), nil
+		},
+		"slurp": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			strs, err := strArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return slang.Slurp(strs[0])
+		},
+		"spit": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) < 2 || len(args) > 3 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 2 or 3 arguments")
+			}
+			strs, err := strArgs(args[:2])
+			if err != nil {
+				return nil, err
+			}
+			appending, err := optionalBool(args, 2)
+			if err != nil {
+				return nil, err
+			}
+			return nil, slang.Spit(strs[0], strs[1], appending)
+		},
+		"read-lines": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			strs, err := strArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return slang.ReadLines(strs[0])
+		},
+		"open-input": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			strs, err := strArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return slang.OpenInput(strs[0])
+		},
+		"open-output": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 or 2 arguments")
+			}
+			strs, err := strArgs(args[:1])
+			if err != nil {
+				return nil, err
+			}
+			appending, err := optionalBool(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return slang.OpenOutput(strs[0], appending)
+		},
+		"read-line": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) > 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected at most 1 argument")
+			}
+			if len(args) == 1 {
+				p, isPort := args[0].(*slang.Port)
+				if !isPort {
+					return nil, fmt.Errorf("%s is not a port", args[0])
+				}
+				return slang.ReadLine(p)
+			}
+			in, err := port(env, slang.InSymbol)
+			if err != nil {
+				return nil, err
+			}
+			return slang.ReadLine(in)
+		},
+		"write": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) < 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+			}
+			p, isPort := args[0].(*slang.Port)
+			if !isPort {
+				return nil, fmt.Errorf("%s is not a port", args[0])
+			}
+			return nil, slang.Write(p, printer.Sprint(args[1:]...))
+		},
+		"print": func(args ...slang.LangType) (slang.LangType, error) {
+			out, err := port(env, slang.OutSymbol)
+			if err != nil {
+				return nil, err
+			}
+			return nil, slang.Write(out, displayArgs(args))
+		},
+		"println": func(args ...slang.LangType) (slang.LangType, error) {
+			out, err := port(env, slang.OutSymbol)
+			if err != nil {
+				return nil, err
+			}
+			return nil, slang.Write(out, displayArgs(args)+"\n")
+		},
+		"printf": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) < 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+			}
+			format, isStr := args[0].(slang.Str)
+			if !isStr {
+				return nil, fmt.Errorf("%s is not a string", args[0])
+			}
+			s, err := printer.Sprintf(string(format), args[1:]...)
+			if err != nil {
+				return nil, err
+			}
+			out, err := port(env, slang.OutSymbol)
+			if err != nil {
+				return nil, err
+			}
+			return nil, slang.Write(out, s)
+		},
+	}
+}
+
+// IOPrimitiveDocs documents IOPrimitives.
+var IOPrimitiveDocs = map[string]slang.SubrDoc{
+	"port?":       {Params: params("x"), Doc: "Returns true if x is a port."},
+	"slurp":       {Params: params("path"), Doc: "Returns the contents of the file at path."},
+	"spit":        {Params: params("path", "content", "&", "append"), Doc: "Writes content to the file at path. The file is overwritten unless append is true."},
+	"read-lines":  {Params: params("path"), Doc: "Returns a vector of the lines of the file at path."},
+	"open-input":  {Params: params("path"), Doc: "Opens the file at path for reading and returns a port. Close it with with-open."},
+	"open-output": {Params: params("path", "&", "append"), Doc: "Opens the file at path for writing and returns a port. Close it with with-open."},
+	"read-line":   {Params: params("&", "port"), Doc: "Reads a line from port, or *in* if no port is given. Returns nil at the end of the input."},
+	"write":       {Params: params("port", "&", "args"), Doc: "Writes the concatenated display representations of args to port."},
+	"print":       {Params: params("&", "args"), Doc: "Writes args separated by spaces to *out*."},
+	"println":     {Params: params("&", "args"), Doc: "Writes args separated by spaces followed by a newline to *out*."},
+	"printf":      {Params: params("fmt", "&", "args"), Doc: "Writes args formatted according to the printf-style format string fmt to *out*."},
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// runProgram evaluates a program with *in* reading from stdin and returns what it wrote to *out*.
+func runProgram(t *testing.T, program, stdin string) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	env := slang.MakeEnv(nil)
+	testSetup(strings.NewReader(stdin), out)(&env)
+
+	exprs, err := parser.Parse(t.Name(), program)
+	if err != nil {
+		t.Fatalf("Parse returned unexpected error %s", err)
+	}
+	for _, expr := range exprs {
+		if _, err := slang.Evaluate(expr, env); err != nil {
+			t.Fatalf("Evaluate(%s) returned unexpected error %s", expr, err)
+		}
+	}
+	return out.String()
+}
+
+var printTests = []struct {
+	program string
+	stdin   string
+	want    string
+}{
+	{`(print "a" 1 [\b "c"])`, "", "a 1 [b c]"},
+	{`(println "total:" 3) (println)`, "", "total: 3\n\n"},
+	{`(printf "%s=%.1f\n" "x" 1.25)`, "", "x=1.2\n"},
+	{`(write *out* "no" "space")`, "", "nospace"},
+	{`(println (read-line) (read-line) (read-line))`, "first\r\nsecond", "first second nil\n"},
+}
+
+func TestPrintPrimitives(t *testing.T) {
+	for _, test := range printTests {
+		if got := runProgram(t, test.program, test.stdin); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+	}
+}
+
+func TestFilePrimitives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+
+	program := `
+(define path ` + "`" + path + "`" + `)
+(spit path "one\n")
+(spit path "two\n" true)
+(println (slurp path))
+(println (read-lines path))
+(with-open [port (open-output path true)]
+  (write port "three\n"))
+(with-open [port (open-input path)]
+  (println (read-line port))
+  (println (read-line port)))
+(println (len (read-lines path)))
+`
+	want := "one\ntwo\n\n[one two]\none\ntwo\n3\n"
+	if got := runProgram(t, program, ""); got != want {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestFilePrimitives", got, want)
+	}
+}
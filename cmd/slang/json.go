@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zachorosz/slang"
+)
+
+// JSONPrimitives is a map with applications of the slang JSON library.
+var JSONPrimitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"json/parse": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.FromJSON([]byte(strs[0]))
+	},
+	"json/stringify": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 or 2 arguments")
+		}
+		pretty, err := optionalBool(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		b, err := slang.ToJSON(args[0], pretty)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Str(b), nil
+	},
+}
+
+// JSONPrimitiveDocs documents JSONPrimitives.
+var JSONPrimitiveDocs = map[string]slang.SubrDoc{
+	"json/parse":     {Params: params("s"), Doc: "Parses the JSON document s. Objects become maps, arrays become vectors and null becomes nil."},
+	"json/stringify": {Params: params("x", "&", "pretty"), Doc: "Returns x encoded as JSON, indented if pretty is true. Procedures, ports and regular expressions cannot be encoded."},
+}
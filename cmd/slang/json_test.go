@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+)
+
+var jsonTests = []struct {
+	program string
+	want    string
+}{
+	{`(println (json/parse "{\"a\": [1, null, true], \"b\": \"x\"}"))`, "{a [1 nil true] b x}\n"},
+	{`(print (json/stringify (assoc (json/parse "{\"port\": 80}") "port" 8080 "debug" false)))`, `{"port":8080,"debug":false}`},
+	{`(print (json/stringify [1 (hash-map 'k "v")] true))`, "[\n  1,\n  {\n    \"k\": \"v\"\n  }\n]"},
+	{`(print (get (json/parse "{\"a\": 1}") "b" "none") (contains? (hash-map 1 2) 1))`, "none true"},
+	{`(print (keys (dissoc (hash-map "a" 1 "b" 2) "a")) (vals (hash-map "a" 1)))`, "[b] [1]"},
+}
+
+func TestJSONPrimitives(t *testing.T) {
+	for _, test := range jsonTests {
+		if got := runProgram(t, test.program, ""); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+	}
+}
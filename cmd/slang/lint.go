@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/lint"
+)
+
+// runLint reports likely mistakes in slang source files, or standard input if no files are named.
+// Diagnostics are printed one per line, or as a JSON array with -json. It returns the exit status: 1
+// if any diagnostics were reported.
+// Usage: `slang lint [-json] [files...]`
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slang lint [-json] [files...]")
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "Print diagnostics as a JSON array")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// programs are linted against the environment they are evaluated in
+	globals := slang.MakeEnv(nil)
+	setupEnv(&globals, 0, nil)
+
+	diagnostics := []lint.Diagnostic{}
+	status := 0
+	if flags.NArg() == 0 {
+		src, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		diagnostics = append(diagnostics, lint.Source("<stdin>", string(src), &globals)...)
+	}
+	for _, filename := range flags.Args() {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+			continue
+		}
+		diagnostics = append(diagnostics, lint.Source(filename, string(src), &globals)...)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		enc.Encode(diagnostics)
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(stdout, diagnostic)
+		}
+	}
+	if len(diagnostics) > 0 {
+		status = 1
+	}
+	return status
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang/lint"
+)
+
+func TestLint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	status := runLint(nil, strings.NewReader("(define f [x] (println x))\n(f 1)\n"), &stdout, &stderr)
+	if status != 0 || stdout.Len() != 0 {
+		t.Errorf("\n%s:\n\tgot %d %q %q\n\texp 0 and no output", "clean", status, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	status = runLint(nil, strings.NewReader("(nth [1])\n(define *ARGV* 1)\n"), &stdout, &stderr)
+	want := "<stdin>:1:1: error: Incorrect number of arguments to nth - expected 2, got 1 (arity-mismatch)\n" +
+		"<stdin>:2:9: warning: Definition '*ARGV*' shadows a builtin (shadowed-builtin)\n"
+	if status != 1 || stdout.String() != want {
+		t.Errorf("\n%s:\n\tgot %d %q\n\texp 1 %q", "diagnostics", status, stdout.String(), want)
+	}
+
+	stdout.Reset()
+	runLint(nil, strings.NewReader("(gen/elements)\n(gen/one-of)\n"), &stdout, &stderr)
+	want = "<stdin>:1:1: error: Incorrect number of arguments to gen/elements - expected at least 1, got 0 (arity-mismatch)\n" +
+		"<stdin>:2:1: error: Incorrect number of arguments to gen/one-of - expected at least 1, got 0 (arity-mismatch)\n"
+	if stdout.String() != want {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "generators", stdout.String(), want)
+	}
+}
+
+func TestLintJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	runLint([]string{"-json"}, strings.NewReader("(if)"), &stdout, &stderr)
+
+	var diagnostics []lint.Diagnostic
+	if err := json.Unmarshal(stdout.Bytes(), &diagnostics); err != nil {
+		t.Fatalf("output is not JSON: %s\n%s", err, stdout.String())
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != lint.MalformedForm || diagnostics[0].End.Column != 5 {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp one malformed-form diagnostic ending at column 5", "TestLintJSON", diagnostics)
+	}
+	if !strings.Contains(stdout.String(), `"severity": "error"`) {
+		t.Errorf("\n%s:\n\tgot %s\n\texp severity by name", "TestLintJSON", stdout.String())
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/lsp"
+)
+
+// runLSP runs a language server over stdin and stdout for editors. It returns the exit status: 1 if
+// the connection failed or the client exited without a shutdown request.
+// Usage: `slang lsp`
+func runLSP(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slang lsp")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	// documents are analyzed against the environment programs are evaluated in
+	globals := slang.MakeEnv(nil)
+	setupEnv(&globals, 0, nil)
+
+	if err := lsp.NewServer(&globals).Serve(stdin, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zachorosz/slang"
+)
+
+// mapArg asserts that x is a Map.
+func mapArg(x slang.LangType) (slang.Map, error) {
+	m, isMap := x.(slang.Map)
+	if !isMap {
+		return slang.Map{}, fmt.Errorf("%s is not a map", x)
+	}
+	return m, nil
+}
+
+// MapPrimitives is a map with applications of the slang Map type.
+var MapPrimitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"hash-map": func(args ...slang.LangType) (slang.LangType, error) {
+		return slang.MakeMap(args...)
+	},
+	"map?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.MapP(args[0]), nil
+	},
+	"get": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 or 3 arguments")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if value, exists := m.Get(args[1]); exists {
+			return value, nil
+		}
+		if len(args) == 3 {
+			return args[2], nil
+		}
+		return nil, nil
+	},
+	"contains?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		_, exists := m.Get(args[1])
+		return exists, nil
+	},
+	"assoc": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 3 || len(args)%2 == 0 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected a map followed by keys and values")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(args); i += 2 {
+			if m, err = m.Assoc(args[i], args[i+1]); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	},
+	"dissoc": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range args[1:] {
+			m = m.Dissoc(key)
+		}
+		return m, nil
+	},
+	"keys": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return m.Keys(), nil
+	},
+	"vals": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		m, err := mapArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return m.Values(), nil
+	},
+}
+
+// MapPrimitiveDocs documents MapPrimitives.
+var MapPrimitiveDocs = map[string]slang.SubrDoc{
+	"hash-map":  {Params: params("&", "kvs"), Doc: "Returns a new map of the alternating keys and values kvs."},
+	"map?":      {Params: params("x"), Doc: "Returns true if x is a map."},
+	"get":       {Params: params("m", "key", "&", "default"), Doc: "Returns the value of key in m, or default (nil) if key is not present."},
+	"contains?": {Params: params("m", "key"), Doc: "Returns true if key is present in m."},
+	"assoc":     {Params: params("m", "key", "value", "&", "kvs"), Doc: "Returns a copy of m with each key mapped to its value."},
+	"dissoc":    {Params: params("m", "&", "keys"), Doc: "Returns a copy of m without keys."},
+	"keys":      {Params: params("m"), Doc: "Returns a vector of the keys of m in insertion order."},
+	"vals":      {Params: params("m"), Doc: "Returns a vector of the values of m in the order of its keys."},
+}
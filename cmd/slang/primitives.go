@@ -4,12 +4,25 @@ import (
 	"fmt"
 
 	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/printer"
 )
 
 // Primitives is a map with applications of slang primitives.
 //
 // This is the core package for slang environments.
 var Primitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"char?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.CharP(args[0]), nil
+	},
+	"inst?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.InstP(args[0]), nil
+	},
 	"list?": func(args ...slang.LangType) (slang.LangType, error) {
 		if len(args) != 1 {
 			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
@@ -40,6 +53,12 @@ var Primitives = map[string]func(...slang.LangType) (slang.LangType, error){
 		}
 		return slang.SequenceP(args[0]), nil
 	},
+	"uuid?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.UUIDP(args[0]), nil
+	},
 	"string?": func(args ...slang.LangType) (slang.LangType, error) {
 		if len(args) != 1 {
 			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
@@ -265,4 +284,16 @@ var Primitives = map[string]func(...slang.LangType) (slang.LangType, error){
 		}
 		return slang.MakeVector(args[0], args[1:]...), nil
 	},
+	"pr-str": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.Str(printer.PrStr(args[0])), nil
+	},
+	"print-str": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.Str(printer.DisplayStr(args[0])), nil
+	},
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// readFirst returns the first form parsed from s by parse.
+func readFirst(parse func(name, input string) ([]slang.LangType, error), s slang.Str) (slang.LangType, error) {
+	forms, err := parse("read-string", string(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(forms) == 0 {
+		return nil, fmt.Errorf("EOF while reading")
+	}
+	return forms[0], nil
+}
+
+// ReaderPrimitives returns the primitives that expose the reader and evaluator to slang programs.
+// eval and make-env use env as the global environment.
+func ReaderPrimitives(env *slang.Env) map[string]func(...slang.LangType) (slang.LangType, error) {
+	return map[string]func(...slang.LangType) (slang.LangType, error){
+		"read-string": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			strs, err := strArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return readFirst(parser.Parse, strs[0])
+		},
+		"read-data": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+			}
+			strs, err := strArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return readFirst(parser.ParseData, strs[0])
+		},
+		"read": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) > 2 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected at most 2 arguments")
+			}
+			var p *slang.Port
+			if len(args) > 0 {
+				var isPort bool
+				if p, isPort = args[0].(*slang.Port); !isPort {
+					return nil, fmt.Errorf("%s is not a port", args[0])
+				}
+			} else {
+				in, err := port(env, slang.InSymbol)
+				if err != nil {
+					return nil, err
+				}
+				p = in
+			}
+			if p.Reader() == nil {
+				return nil, fmt.Errorf("%s is not an input port", p)
+			}
+			r := p.FormReader()
+			if r == nil {
+				// the port's buffered reader is shared; read never consumes input past the current line
+				r = parser.NewReader(p.String(), p.Reader())
+				p.SetFormReader(r)
+			}
+			form, err := r.Next()
+			if err == io.EOF {
+				if len(args) == 2 {
+					return args[1], nil
+				}
+				return nil, fmt.Errorf("EOF while reading %s", p)
+			}
+			return form, err
+		},
+		"eval": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 1 or 2 arguments")
+			}
+			if len(args) == 1 {
+				return slang.Evaluate(args[0], *env)
+			}
+			evalEnv, isEnv := args[1].(*slang.Env)
+			if !isEnv {
+				return nil, fmt.Errorf("%s is not an environment", args[1])
+			}
+			return slang.Evaluate(args[0], *evalEnv)
+		},
+		"make-env": func(args ...slang.LangType) (slang.LangType, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("Incorrect number of arguments - expected 0 arguments")
+			}
+			child := slang.MakeEnv(env)
+			return &child, nil
+		},
+	}
+}
+
+// ReaderPrimitiveDocs documents ReaderPrimitives.
+var ReaderPrimitiveDocs = map[string]slang.SubrDoc{
+	"read-string": {Params: params("s"), Doc: "Returns the first form read from s without evaluating it."},
+	"read-data":   {Params: params("s"), Doc: "Returns the first form read from s as data. Quote and regular expressions are not allowed; maps and tagged literals like #inst and #uuid are."},
+	"read":        {Params: params("&", "port", "eof"), Doc: "Reads the next form from port, or *in* if no port is given. Returns eof at the end of the input, or fails if eof is not given."},
+	"eval":        {Params: params("form", "&", "env"), Doc: "Evaluates form in env, or the global environment if no env is given."},
+	"make-env":    {Params: params(), Doc: "Returns a new environment enclosed by the global environment, for use with eval."},
+}
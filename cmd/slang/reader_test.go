@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+var readerTests = []struct {
+	program string
+	stdin   string
+	want    string
+}{
+	{`(print (read-string "(+ 1 2) ignored"))`, "", "(+ 1 2)"},
+	{`(print (eval (read-string "(+ 1 2)")))`, "", "3"},
+	{`(print (pr-str (read-data "{\"port\" 80 id #uuid \"f81d4fae-7dec-11d0-a765-00a0c91e6bf6\"}")))`, "",
+		`{"port" 80 id #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"}`},
+	{`(print (inst? (read-data "#inst \"2020-01-02\"")) (uuid? 1))`, "", "true false"},
+	{`(print (read) (read) (read) (read *in* 'done))`, "1 [a\nb] \"c\"\n\n", "1 [a b] c done"},
+	{`(define e (make-env)) (eval '(define x 1) e) (print (eval 'x e) (eval '(nil? 1)))`, "", "1 false"},
+}
+
+func TestReaderPrimitives(t *testing.T) {
+	for _, test := range readerTests {
+		if got := runProgram(t, test.program, test.stdin); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+	}
+}
+
+func TestEvaluateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "partial.sl")
+	ioutil.WriteFile(filename, []byte("(define x\n  2)\n(println (* x x))\n(println x))\n"), 0644)
+
+	out := &bytes.Buffer{}
+	env := slang.MakeEnv(nil)
+	testSetup(strings.NewReader(""), out)(&env)
+
+	// forms are evaluated as they are read, up to the syntax error
+	err = evaluateFile(env, filename, nil)
+	if err == nil || !strings.Contains(err.Error(), "Unexpected ')'") {
+		t.Errorf("\n%s:\n\tgot %v\n\texp syntax error", "TestEvaluateFile", err)
+	}
+	if got := out.String(); got != "4\n2\n" {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestEvaluateFile", got, "4\n2\n")
+	}
+}
+
+func TestEvaluateFileResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "results.sl")
+	ioutil.WriteFile(filename, []byte("(define x 2)\n(println \"x\")\n[x \"s\"]\n"), 0644)
+
+	out, results := &bytes.Buffer{}, &bytes.Buffer{}
+	env := slang.MakeEnv(nil)
+	testSetup(strings.NewReader(""), out)(&env)
+
+	if err := evaluateFile(env, filename, results); err != nil {
+		t.Fatalf("evaluateFile returned unexpected error %s", err)
+	}
+	if got, want := results.String(), "2\nnil\n[2 \"s\"]\n"; got != want || out.String() != "x\n" {
+		t.Errorf("\n%s:\n\tgot %q %q\n\texp %q %q", "TestEvaluateFileResults", got, out.String(), want, "x\n")
+	}
+}
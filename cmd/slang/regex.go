@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zachorosz/slang"
+)
+
+// regexArgs asserts that the first argument is a Regex and the rest are strings.
+func regexArgs(args []slang.LangType) (slang.Regex, []slang.Str, error) {
+	re, isRegex := args[0].(slang.Regex)
+	if !isRegex {
+		return slang.Regex{}, nil, fmt.Errorf("%s is not a regular expression", args[0])
+	}
+	strs, err := strArgs(args[1:])
+	return re, strs, err
+}
+
+// RegexPrimitives is a map with applications of the slang regular expression library.
+var RegexPrimitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"regex?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		return slang.RegexP(args[0]), nil
+	},
+	"re-pattern": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.RePattern(strs[0])
+	},
+	"re-find": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		re, strs, err := regexArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.ReFind(re, strs[0]), nil
+	},
+	"re-matches": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		re, strs, err := regexArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.ReMatches(re, strs[0]), nil
+	},
+	"re-seq": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		re, strs, err := regexArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.ReSeq(re, strs[0]), nil
+	},
+	"re-groups": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		re, strs, err := regexArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.ReGroups(re, strs[0]), nil
+	},
+	"re-replace": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 3 arguments")
+		}
+		re, strs, err := regexArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.ReReplace(re, strs[0], strs[1]), nil
+	},
+}
+
+// RegexPrimitiveDocs documents RegexPrimitives.
+var RegexPrimitiveDocs = map[string]slang.SubrDoc{
+	"regex?":     {Params: params("x"), Doc: "Returns true if x is a regular expression."},
+	"re-pattern": {Params: params("s"), Doc: "Compiles the string s into a regular expression."},
+	"re-find":    {Params: params("re", "s"), Doc: "Returns the first match of re in s, or nil. If re has groups, the match is a vector of the whole match and each capture."},
+	"re-matches": {Params: params("re", "s"), Doc: "Returns the match of re if it matches all of s, or nil. If re has groups, the match is a vector of the whole match and each capture."},
+	"re-seq":     {Params: params("re", "s"), Doc: "Returns a vector of every match of re in s, each in the form returned by re-find."},
+	"re-groups":  {Params: params("re", "s"), Doc: "Returns a vector of the whole first match of re in s and each capture, or nil."},
+	"re-replace": {Params: params("re", "s", "replacement"), Doc: "Returns s with every match of re replaced. $1 or ${name} in replacement refer to captures."},
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+	"github.com/zachorosz/slang/printer"
+)
+
+const (
+	replExit         = "(exit)"
+	replPrompt       = "slang> "
+	replContinuation = "  ...> "
+	historyFile      = ".slang_history"
+	historyLimit     = 1000 // entries recalled by the REPL; the file is trimmed at twice as many
+)
+
+// replHelp lists the REPL meta-commands in the order they are shown by `:help`.
+var replHelp = [][2]string{
+	{":help", "show this message"},
+	{":env", "list the symbols defined in the environment"},
+	{":load file", "evaluate every form in file"},
+	{":reset", "discard all definitions and start with a fresh environment"},
+	{":time expr", "evaluate expr and print the elapsed time"},
+	{":doc sym", "describe the definition of sym"},
+	{":quit", "exit the REPL"},
+}
+
+// resultSymbols hold the most recent REPL results, newest first. errorSymbol holds the last error.
+var (
+	resultSymbols = []slang.Symbol{"*1", "*2", "*3"}
+	errorSymbol   = slang.Symbol("*e")
+)
+
+// lineReader reads one line of REPL input after displaying prefix. io.EOF ends the REPL.
+type lineReader func(prefix string) (string, error)
+
+type repl struct {
+	env         *slang.Env
+	setup       func(*slang.Env)
+	read        lineReader
+	out         io.Writer
+	history     []string
+	historyPath string // file the history is saved to, or "" if it is not saved
+	historySize int    // number of entries in the history file
+}
+
+// newREPL constructs a REPL evaluating in env. setup is called to populate a fresh environment
+// when the REPL is reset.
+func newREPL(env *slang.Env, setup func(*slang.Env), read lineReader, out io.Writer) *repl {
+	return &repl{
+		env:   env,
+		setup: setup,
+		read:  read,
+		out:   out,
+	}
+}
+
+// scanLines returns a lineReader that writes each prefix to out and scans lines from in.
+func scanLines(in io.Reader, out io.Writer) lineReader {
+	scanner := bufio.NewScanner(in)
+	return func(prefix string) (string, error) {
+		fmt.Fprint(out, prefix)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+}
+
+// promptLine reads a line with go-prompt, offering completions and the REPL history.
+func (r *repl) promptLine(prefix string) (string, error) {
+	return prompt.Input(prefix, completer,
+		prompt.OptionCompletionWordSeparator(wordSeparators),
+		prompt.OptionHistory(r.history)), nil
+}
+
+// useHistoryFile restores history from path; entered lines are saved to it afterwards.
+func (r *repl) useHistoryFile(path string) {
+	r.historyPath = path
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if line != "" {
+			r.history = append(r.history, line)
+		}
+	}
+	r.historySize = len(r.history)
+	if len(r.history) > historyLimit {
+		r.history = r.history[len(r.history)-historyLimit:]
+	}
+}
+
+// remember records an entry in the history, keeping the last historyLimit entries. If a history
+// file is in use, the entry is appended to it; once the file holds twice historyLimit entries, it
+// is rewritten with the entries kept. The lines of multi-line entries are joined with spaces so that
+// they can be recalled as a single line.
+func (r *repl) remember(entry string) {
+	entry = strings.Replace(strings.TrimSpace(entry), "\n", " ", -1)
+	if entry == "" {
+		return
+	}
+	r.history = append(r.history, entry)
+	if len(r.history) > historyLimit {
+		r.history = r.history[len(r.history)-historyLimit:]
+	}
+
+	if r.historyPath == "" {
+		return
+	}
+	if err := r.saveHistory(entry); err != nil {
+		// the history is kept for the session, but no longer saved
+		fmt.Fprintf(r.out, "Cannot save history: %s\n", err)
+		r.historyPath = ""
+	}
+}
+
+// saveHistory appends entry to the history file, or rewrites the file with the history if it has
+// grown to twice historyLimit entries.
+func (r *repl) saveHistory(entry string) error {
+	if r.historySize+1 >= 2*historyLimit {
+		r.historySize = len(r.history)
+		return ioutil.WriteFile(r.historyPath, []byte(strings.Join(r.history, "\n")+"\n"), 0600)
+	}
+	f, err := os.OpenFile(r.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	r.historySize++
+	if _, err := f.WriteString(entry + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// run reads lines until the buffered input holds only complete forms. A continuation prompt is
+// shown while parens, brackets or strings are left open; then every buffered form is evaluated.
+// Lines beginning with ':' are meta-commands.
+func (r *repl) run() error {
+	r.bindResultSymbols()
+
+	buffer := ""
+	for {
+		prefix := replPrompt
+		if buffer != "" {
+			prefix = replContinuation
+		}
+
+		line, err := r.read(prefix)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if buffer == "" {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == replExit {
+				return nil
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				r.remember(trimmed)
+				if quit := r.command(trimmed); quit {
+					return nil
+				}
+				continue
+			}
+		}
+
+		buffer += line + "\n"
+		exprs, err := parser.Parse("REPL", buffer)
+		if parser.IsIncomplete(err) {
+			continue
+		}
+		r.remember(buffer)
+		buffer = ""
+
+		if err != nil {
+			r.fail(err)
+			continue
+		}
+		r.evaluatePrint(exprs)
+	}
+}
+
+// command runs a meta-command and returns true if the REPL should exit.
+func (r *repl) command(line string) bool {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i > -1 {
+		name, arg = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	switch name {
+	case ":help":
+		for _, help := range replHelp {
+			fmt.Fprintf(r.out, "%-12s %s\n", help[0], help[1])
+		}
+	case ":env":
+		r.listEnv()
+	case ":load":
+		r.load(arg)
+	case ":reset":
+		// a hook, like the profiler, is kept in the fresh environment
+		hook := r.env.Hook()
+		*r.env = slang.MakeEnv(nil)
+		r.env.SetHook(hook)
+		r.setup(r.env)
+		r.bindResultSymbols()
+	case ":time":
+		exprs, err := parser.Parse("REPL", arg)
+		if err != nil {
+			r.fail(err)
+			break
+		}
+		start := time.Now()
+		r.evaluatePrint(exprs)
+		fmt.Fprintf(r.out, "Elapsed time: %s\n", time.Since(start))
+	case ":doc":
+		r.doc(slang.Symbol(arg))
+	case ":quit":
+		return true
+	default:
+		fmt.Fprintf(r.out, "Unknown command %s - see :help\n", name)
+	}
+	return false
+}
+
+// evaluatePrint evaluates each form and prints its result. Successful results are bound to the
+// result symbols. Evaluation stops at the first error.
+func (r *repl) evaluatePrint(exprs []slang.LangType) {
+	for _, expr := range exprs {
+		result, err := slang.Evaluate(expr, *r.env)
+		if err != nil {
+			r.fail(err)
+			return
+		}
+		r.pushResult(result)
+		printer.Fprintln(r.out, result, printer.Readable)
+	}
+}
+
+// fail prints err and binds it to the error symbol.
+func (r *repl) fail(err error) {
+	printError(r.out, err)
+	r.env.Mutate(errorSymbol, slang.Str(err.Error()))
+}
+
+// pushResult shifts the previous results down the result symbols and binds result to the first.
+func (r *repl) pushResult(result slang.LangType) {
+	for i := len(resultSymbols) - 1; i > 0; i-- {
+		previous, _ := r.env.Get(resultSymbols[i-1])
+		r.env.Mutate(resultSymbols[i], previous)
+	}
+	r.env.Mutate(resultSymbols[0], result)
+}
+
+// bindResultSymbols defines the result and error symbols as nil if they are not yet defined.
+func (r *repl) bindResultSymbols() {
+	for _, symbol := range append(resultSymbols, errorSymbol) {
+		r.env.Define(symbol, nil)
+	}
+}
+
+func (r *repl) listEnv() {
+	for _, s := range suggestions(r.env) {
+		if s.Description != "special form" {
+			fmt.Fprintf(r.out, "%-16s %s\n", s.Text, s.Description)
+		}
+	}
+}
+
+func (r *repl) load(filename string) {
+	if filename == "" {
+		fmt.Fprintln(r.out, "Usage: :load file")
+		return
+	}
+	if err := evaluateFile(*r.env, filename, nil); err != nil {
+		r.fail(err)
+		return
+	}
+	fmt.Fprintf(r.out, "Loaded %s\n", filename)
+}
+
+func (r *repl) doc(symbol slang.Symbol) {
+	if symbol == "" {
+		fmt.Fprintln(r.out, "Usage: :doc sym")
+		return
+	}
+	if doc, isSpecialForm := slang.SpecialFormDocs[symbol]; isSpecialForm {
+		fmt.Fprintf(r.out, "%s\n  special form %s\n", symbol, doc)
+		return
+	}
+	value, err := r.env.Get(symbol)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	if doc, err := slang.Doc(value); err == nil {
+		fmt.Fprintln(r.out, string(doc))
+		return
+	}
+	fmt.Fprintf(r.out, "%s\n  %s\n", symbol, describe(value))
+}
+
+// defaultHistoryPath returns the path of the history file in the user's home directory.
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFile)
+}
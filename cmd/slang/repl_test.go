@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+// testSetup returns a setup function that loads the primitive packages and binds the standard
+// ports to in and out.
+func testSetup(in io.Reader, out io.Writer) func(*slang.Env) {
+	return func(env *slang.Env) {
+		env.UseSubrPackage("core", Primitives, PrimitiveDocs)
+		env.UseSubrPackage("doc", DocPrimitives(env), DocPrimitiveDocs)
+		env.UseSubrPackage("strings", StringPrimitives, StringPrimitiveDocs)
+		env.UseSubrPackage("regex", RegexPrimitives, RegexPrimitiveDocs)
+		env.UseSubrPackage("io", IOPrimitives(env), IOPrimitiveDocs)
+		env.UseSubrPackage("maps", MapPrimitives, MapPrimitiveDocs)
+		env.UseSubrPackage("json", JSONPrimitives, JSONPrimitiveDocs)
+		env.UseSubrPackage("reader", ReaderPrimitives(env), ReaderPrimitiveDocs)
+		env.UseSubrPackage("gen", GenPrimitives, GenPrimitiveDocs)
+		env.UsePorts(in, out, out)
+	}
+}
+
+// runTestREPL drives a REPL with input and returns everything it wrote.
+func runTestREPL(t *testing.T, input string) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	setup := testSetup(strings.NewReader(""), out)
+	env := slang.MakeEnv(nil)
+	setup(&env)
+
+	r := newREPL(&env, setup, scanLines(strings.NewReader(input), out), out)
+	if err := r.run(); err != nil {
+		t.Fatalf("run returned unexpected error %s", err)
+	}
+	return out.String()
+}
+
+var replTests = []struct {
+	name     string
+	input    string
+	contains []string
+}{
+	{"multiple forms", "(+ 1 2) (+ 3 4)\n", []string{"3\n", "7\n"}},
+	{"multi-line form", "(+ 1\n2)\n", []string{replContinuation, "3\n"}},
+	{"result symbols", "1\n2\n3\n(vec *1 *2 *3)\n", []string{"[3 2 1]"}},
+	{"error symbol", "undefined-sym\n*e\n", []string{"\"Symbol 'undefined-sym' is undefined\""}},
+	{"help", ":help\n", []string{":load file", ":quit"}},
+	{"env", "(define x 1)\n:env\n", []string{"x ", "value", "procedure (core)"}},
+	{"reset", "(define x 1)\n:reset\nx\n", []string{"Symbol 'x' is undefined"}},
+	{"time", ":time (+ 1 2)\n", []string{"3\n", "Elapsed time: "}},
+	{"doc", ":doc define\n(define f [a b] \"Returns a.\" a)\n:doc f\n", []string{"special form", "f [a b]\n  Returns a.\n"}},
+	{"doc primitive", ":doc nth\n", []string{"nth [seq n]\n  Returns the nth (zero-based) item of seq.\n"}},
+	{"arglist", "(arglist list)\n", []string{"[item & items]"}},
+	{"apropos", "(apropos \"vec\")\n", []string{"[gen/vector-of vec vec?]"}},
+	{"unknown command", ":frobnicate\n", []string{"Unknown command :frobnicate"}},
+	{"syntax error", "(+ 1 ]\n", []string{"REPL:1:6: Unexpected ']'\n1 | (+ 1 ]\n  |      ^\n"}},
+}
+
+func TestREPL(t *testing.T) {
+	for _, test := range replTests {
+		got := runTestREPL(t, test.input)
+		for _, want := range test.contains {
+			if !strings.Contains(got, want) {
+				t.Errorf("\n%s:\n\tgot %q\n\texp to contain %q", test.name, got, want)
+			}
+		}
+	}
+}
+
+func TestREPLQuitStopsReading(t *testing.T) {
+	for _, input := range []string{":quit\n(+ 1 2)\n", "(exit)\n(+ 1 2)\n"} {
+		if got := runTestREPL(t, input); strings.Contains(got, "3") {
+			t.Errorf("\n%s:\n\tgot %q\n\texp no evaluation after exit", "TestREPLQuitStopsReading", got)
+		}
+	}
+}
+
+// countingHook counts the forms evaluated with it.
+type countingHook struct {
+	evals int
+}
+
+func (h *countingHook) Eval(expr slang.LangType, env slang.Env, stack []slang.Frame) error {
+	h.evals++
+	return nil
+}
+
+func (h *countingHook) Apply(stack []slang.Frame) error {
+	return nil
+}
+
+func TestREPLResetKeepsHook(t *testing.T) {
+	out := &bytes.Buffer{}
+	setup := testSetup(strings.NewReader(""), out)
+	env := slang.MakeEnv(nil)
+	setup(&env)
+	hook := &countingHook{}
+	env.SetHook(hook)
+	r := newREPL(&env, setup, scanLines(strings.NewReader(":reset\n(+ 1 2)\n"), out), out)
+	if err := r.run(); err != nil {
+		t.Fatalf("run returned unexpected error %s", err)
+	}
+	if env.Hook() != hook || hook.evals == 0 {
+		t.Errorf("\n%s:\n\tgot %v with %d evaluations\n\texp the hook to be kept", "TestREPLResetKeepsHook", env.Hook(), hook.evals)
+	}
+}
+
+func TestREPLLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "square.sl")
+	ioutil.WriteFile(filename, []byte("(define square [x] (* x x))\n"), 0644)
+
+	got := runTestREPL(t, ":load "+filename+"\n(square 3)\n")
+	for _, want := range []string{"Loaded " + filename, "9\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("\n%s:\n\tgot %q\n\texp to contain %q", "TestREPLLoad", got, want)
+		}
+	}
+}
+
+func TestREPLHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, historyFile)
+	ioutil.WriteFile(path, []byte("(+ 1 1)\n"), 0600)
+
+	out := &bytes.Buffer{}
+	setup := testSetup(strings.NewReader(""), out)
+	env := slang.MakeEnv(nil)
+	setup(&env)
+	r := newREPL(&env, setup, scanLines(strings.NewReader("(+ 1\n2)\n:env\n(str \"a   b\")\n"), out), out)
+	r.useHistoryFile(path)
+	if err := r.run(); err != nil {
+		t.Fatalf("run returned unexpected error %s", err)
+	}
+
+	b, _ := ioutil.ReadFile(path)
+	want := "(+ 1 1)\n(+ 1 2)\n:env\n(str \"a   b\")\n"
+	if string(b) != want {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestREPLHistory", string(b), want)
+	}
+	if len(r.history) != 4 {
+		t.Errorf("\n%s:\n\tgot %d history entries\n\texp 4", "TestREPLHistory", len(r.history))
+	}
+}
+
+func TestREPLHistoryLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// entries are appended until the file holds twice historyLimit, and then it is trimmed
+	for _, c := range []struct {
+		saved, want int
+	}{
+		{historyLimit, historyLimit + 1},
+		{2*historyLimit - 1, historyLimit},
+	} {
+		path := filepath.Join(dir, historyFile)
+		ioutil.WriteFile(path, []byte(strings.Repeat("(+ 1 1)\n", c.saved)), 0600)
+
+		out := &bytes.Buffer{}
+		setup := testSetup(strings.NewReader(""), out)
+		env := slang.MakeEnv(nil)
+		setup(&env)
+		r := newREPL(&env, setup, scanLines(strings.NewReader("(+ 1 2)\n"), out), out)
+		r.useHistoryFile(path)
+		if err := r.run(); err != nil {
+			t.Fatalf("run returned unexpected error %s", err)
+		}
+
+		b, _ := ioutil.ReadFile(path)
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		if len(lines) != c.want || lines[len(lines)-1] != "(+ 1 2)" {
+			t.Errorf("\n%s:\n\tgot %d lines ending in %q\n\texp %d ending in %q", "TestREPLHistoryLimit",
+				len(lines), lines[len(lines)-1], c.want, "(+ 1 2)")
+		}
+		if len(r.history) != historyLimit {
+			t.Errorf("\n%s:\n\tgot %d history entries\n\texp %d", "TestREPLHistoryLimit", len(r.history), historyLimit)
+		}
+	}
+}
+
+func TestREPLHistoryError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := &bytes.Buffer{}
+	setup := testSetup(strings.NewReader(""), out)
+	env := slang.MakeEnv(nil)
+	setup(&env)
+	r := newREPL(&env, setup, scanLines(strings.NewReader("1\n2\n"), out), out)
+	r.useHistoryFile(filepath.Join(dir, "missing", historyFile))
+	if err := r.run(); err != nil {
+		t.Fatalf("run returned unexpected error %s", err)
+	}
+
+	if got := strings.Count(out.String(), "Cannot save history"); got != 1 || len(r.history) != 2 {
+		t.Errorf("\n%s:\n\tgot %d errors, %d entries in %q\n\texp 1 error, 2 entries", "TestREPLHistoryError",
+			got, len(r.history), out.String())
+	}
+}
@@ -3,115 +3,178 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 
-	"github.com/c-bata/go-prompt"
 	"github.com/zachorosz/slang"
 	"github.com/zachorosz/slang/parser"
-)
-
-const (
-	replExit = "(exit)"
+	"github.com/zachorosz/slang/printer"
+	"github.com/zachorosz/slang/profile"
 )
 
 var (
 	expression = flag.String("e", "", "Evaluate expression and print")
-	program    = ""
+	cpuprofile = flag.String("cpuprofile", "", "Write a pprof profile of the procedures of the program to `file`")
 	env        = slang.MakeEnv(nil)
 )
 
+// commands are tools run by naming them as the first argument, like `slang fmt file.sl`. A command
+// returns the exit status of the program.
+var commands = map[string]func(args []string, stdin io.Reader, stdout, stderr io.Writer) int{
+	"debug": runDebug,
+	"fmt":   runFmt,
+	"lint":  runLint,
+	"lsp":   runLSP,
+	"test":  runTest,
+}
+
 func usage() {
-	fmt.Println("usage: slang [[-e expression | filename] [arguments]]")
+	fmt.Println("usage: slang [-cpuprofile file] [[-e expression | filename] [arguments]]")
+	fmt.Println("       slang debug file [arguments]")
+	fmt.Println("       slang fmt [-w] [-d] [-width n] [files...]")
+	fmt.Println("       slang lint [-json] [files...]")
+	fmt.Println("       slang lsp")
+	fmt.Println("       slang test [-v] [-junit file] [files or directories...]")
 	flag.PrintDefaults()
 	os.Exit(2)
 }
 
-func readEvaluatePrint(sexpr string) bool {
-	expr, err := parser.Parse("REPL", sexpr)
-	if err != nil {
-		fmt.Printf("%s\n", err)
-		return false
+// printError writes err to w. Syntax errors are followed by the offending source line.
+func printError(w io.Writer, err error) {
+	fmt.Fprintln(w, err)
+	if parseErr, isParseErr := err.(parser.ParseError); isParseErr {
+		fmt.Fprint(w, parseErr.Snippet())
 	}
+}
 
-	result, err := slang.Evaluate(expr[0], env)
-	if err != nil {
-		fmt.Printf("%s\n", err)
-		return false
+// evaluatePrint evaluates each form and prints its result. Evaluation stops at the first error.
+func evaluatePrint(exprs []slang.LangType) bool {
+	for _, expr := range exprs {
+		result, err := slang.Evaluate(expr, env)
+		if err != nil {
+			fmt.Printf("%s\n", err)
+			return false
+		}
+		printer.Fprintln(os.Stdout, result, printer.Readable)
 	}
-
-	fmt.Println(result)
-
 	return true
 }
 
-func completer(d prompt.Document) []prompt.Suggest {
-	// TODO(zachorosz): add autocomplete suggestions
-	s := []prompt.Suggest{}
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+func readEvaluatePrint(sexpr string) bool {
+	exprs, err := parser.Parse("REPL", sexpr)
+	if err != nil {
+		printError(os.Stdout, err)
+		return false
+	}
+	return evaluatePrint(exprs)
 }
 
-func runREPL() {
+// evaluateFile reads and evaluates the forms of a file one at a time, printing the result of each
+// form to results unless it is nil. Evaluation stops at the first syntax or evaluation error.
+func evaluateFile(env slang.Env, filename string, results io.Writer) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := parser.NewReader(filename, f)
 	for {
-		line := prompt.Input("slang> ", completer)
-		if line == replExit {
-			return
+		expr, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		result, err := slang.Evaluate(expr, env)
+		if err != nil {
+			return err
+		}
+		if results != nil {
+			printer.Fprintln(results, result, printer.Readable)
 		}
-		readEvaluatePrint(line)
 	}
 }
 
-func readFile(filename string) string {
-	b, err := ioutil.ReadFile(filename)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	return string(b)
+func setupEnv(env *slang.Env, argc int, args []string) {
+	setupEnvPorts(env, argc, args, os.Stdin, os.Stdout, os.Stderr)
 }
 
-func setupEnv(env *slang.Env, argc int, args []string) {
+// setupEnvPorts sets up env like setupEnv, with the standard ports bound to in, out and errOut.
+func setupEnvPorts(env *slang.Env, argc int, args []string, in io.Reader, out, errOut io.Writer) {
 	narg := slang.Number(argc)
 	argv := make(slang.Vector, argc)
 	for i, arg := range args {
 		argv[i] = slang.Str(arg)
 	}
 
-	env.UseSubrPackage("", Primitives)
+	env.UseSubrPackage("core", Primitives, PrimitiveDocs)
+	env.UseSubrPackage("doc", DocPrimitives(env), DocPrimitiveDocs)
+	env.UseSubrPackage("strings", StringPrimitives, StringPrimitiveDocs)
+	env.UseSubrPackage("regex", RegexPrimitives, RegexPrimitiveDocs)
+	env.UseSubrPackage("io", IOPrimitives(env), IOPrimitiveDocs)
+	env.UseSubrPackage("maps", MapPrimitives, MapPrimitiveDocs)
+	env.UseSubrPackage("json", JSONPrimitives, JSONPrimitiveDocs)
+	env.UseSubrPackage("reader", ReaderPrimitives(env), ReaderPrimitiveDocs)
+	env.UseSubrPackage("gen", GenPrimitives, GenPrimitiveDocs)
+	env.UsePorts(in, out, errOut)
 	env.Define(slang.Symbol("*ARGV*"), argv)
 	env.Define(slang.Symbol("*NARG*"), narg)
 }
 
+// profiler profiles the program if the -cpuprofile flag is set.
+var profiler *profile.Profiler
+
+// exit writes the profile of the program, if it is profiled, and exits with status.
+func exit(status int) {
+	if profiler != nil {
+		env.SetHook(nil)
+		if err := writeProfile(*cpuprofile, profiler); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+func writeProfile(filename string, p *profile.Profiler) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			os.Exit(command(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
 	flag.Usage = usage
 	flag.Parse()
 
-	if flag.NFlag() > 1 {
-		flag.Usage() // Exit(2)
+	if *cpuprofile != "" {
+		profiler = profile.New()
+		env.SetHook(profiler)
 	}
 
 	// filename passed as argument
-	if flag.NFlag() == 0 && flag.NArg() > 0 {
+	if *expression == "" && flag.NArg() > 0 {
 		var filename = flag.Arg(0)
-		program = readFile(filename)
 
 		args := flag.Args()[1:]
 		argc := len(args)
 		setupEnv(&env, argc, args)
 
-		exprs, err := parser.Parse(filename, program)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-
-		for _, expr := range exprs {
-			v, err := slang.Evaluate(expr, env)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-			fmt.Println(v)
+		if err := evaluateFile(env, filename, os.Stdout); err != nil {
+			printError(os.Stderr, err)
+			exit(1)
 		}
 	} else {
 		// run REPL or evaluate expression passed via -e flag
@@ -120,12 +183,20 @@ func main() {
 		if *expression != "" {
 			ok := readEvaluatePrint(*expression)
 			if !ok {
-				os.Exit(1)
+				exit(1)
 			}
 		} else {
-			runREPL()
+			r := newREPL(&env, func(env *slang.Env) {
+				setupEnv(env, flag.NArg(), flag.Args())
+			}, nil, os.Stdout)
+			r.read = r.promptLine
+			r.useHistoryFile(defaultHistoryPath())
+			if err := r.run(); err != nil {
+				fmt.Println(err)
+				exit(1)
+			}
 		}
 	}
 
-	os.Exit(0)
+	exit(0)
 }
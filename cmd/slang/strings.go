@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/printer"
+)
+
+// strArgs asserts that every argument is a string.
+func strArgs(args []slang.LangType) ([]slang.Str, error) {
+	strs := make([]slang.Str, len(args))
+	for i, arg := range args {
+		s, isStr := arg.(slang.Str)
+		if !isStr {
+			return nil, fmt.Errorf("%s is not a string", printer.PrStr(arg))
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// StringPrimitives is a map with applications of the slang string library.
+var StringPrimitives = map[string]func(...slang.LangType) (slang.LangType, error){
+	"str": func(args ...slang.LangType) (slang.LangType, error) {
+		return slang.Str(printer.Sprint(args...)), nil
+	},
+	"substring": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 or 3 arguments")
+		}
+		s, isStr := args[0].(slang.Str)
+		if !isStr {
+			return nil, fmt.Errorf("%s is not a string", args[0])
+		}
+		start, isNumber := args[1].(slang.Number)
+		if !isNumber {
+			return nil, fmt.Errorf("%s is not a valid number", args[1])
+		}
+		end := s.Len()
+		if len(args) == 3 {
+			if end, isNumber = args[2].(slang.Number); !isNumber {
+				return nil, fmt.Errorf("%s is not a valid number", args[2])
+			}
+		}
+		return slang.Substring(s, start, end)
+	},
+	"split": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Split(strs[0], strs[1]), nil
+	},
+	"join": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		sep, isStr := args[0].(slang.Str)
+		if !isStr {
+			return nil, fmt.Errorf("%s is not a string", args[0])
+		}
+		seq, isSeq := args[1].(slang.Sequence)
+		if !isSeq {
+			return nil, fmt.Errorf("%s is not a sequence", args[1])
+		}
+		items := make([]string, 0, int(seq.Len()))
+		for _, item := range slang.ToSlice(seq) {
+			items = append(items, printer.DisplayStr(item))
+		}
+		return slang.Str(strings.Join(items, string(sep))), nil
+	},
+	"trim": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Trim(strs[0]), nil
+	},
+	"upper": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Upper(strs[0]), nil
+	},
+	"lower": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Lower(strs[0]), nil
+	},
+	"index-of": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.IndexOf(strs[0], strs[1]), nil
+	},
+	"starts-with?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.StartsWithP(strs[0], strs[1]), nil
+	},
+	"ends-with?": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 2 arguments")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.EndsWithP(strs[0], strs[1]), nil
+	},
+	"replace": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 3 arguments")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.Replace(strs[0], strs[1], strs[2]), nil
+	},
+	"format": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected at least 1 argument")
+		}
+		format, isStr := args[0].(slang.Str)
+		if !isStr {
+			return nil, fmt.Errorf("%s is not a string", args[0])
+		}
+		s, err := printer.Sprintf(string(format), args[1:]...)
+		return slang.Str(s), err
+	},
+	"string->number": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.StringToNumber(strs[0])
+	},
+	"number->string": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		n, isNumber := args[0].(slang.Number)
+		if !isNumber {
+			return nil, fmt.Errorf("%s is not a valid number", args[0])
+		}
+		return slang.NumberToString(n), nil
+	},
+	"string->symbol": func(args ...slang.LangType) (slang.LangType, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Incorrect number of arguments - expected 1 argument")
+		}
+		strs, err := strArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return slang.StringToSymbol(strs[0])
+	},
+}
+
+// StringPrimitiveDocs documents StringPrimitives.
+var StringPrimitiveDocs = map[string]slang.SubrDoc{
+	"str":            {Params: params("&", "args"), Doc: "Returns the concatenated display representations of args."},
+	"substring":      {Params: params("s", "start", "&", "end"), Doc: "Returns the characters of s from start up to, but not including, end. end defaults to the length of s."},
+	"split":          {Params: params("s", "sep"), Doc: "Returns a vector of the substrings of s separated by sep."},
+	"join":           {Params: params("sep", "seq"), Doc: "Returns the display representations of the items of seq separated by sep."},
+	"trim":           {Params: params("s"), Doc: "Returns s without leading and trailing whitespace."},
+	"upper":          {Params: params("s"), Doc: "Returns s in upper case."},
+	"lower":          {Params: params("s"), Doc: "Returns s in lower case."},
+	"index-of":       {Params: params("s", "substr"), Doc: "Returns the index of the first instance of substr in s, or -1 if substr is not present."},
+	"starts-with?":   {Params: params("s", "prefix"), Doc: "Returns true if s begins with prefix."},
+	"ends-with?":     {Params: params("s", "suffix"), Doc: "Returns true if s ends with suffix."},
+	"replace":        {Params: params("s", "old", "new"), Doc: "Returns s with every instance of old replaced by new."},
+	"format":         {Params: params("fmt", "&", "args"), Doc: "Returns args formatted according to the printf-style format string fmt."},
+	"string->number": {Params: params("s"), Doc: "Converts a string to a number."},
+	"number->string": {Params: params("n"), Doc: "Converts a number to a string."},
+	"string->symbol": {Params: params("s"), Doc: "Converts a string to a symbol."},
+}
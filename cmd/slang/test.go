@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/format"
+	"github.com/zachorosz/slang/printer"
+)
+
+// testSuffix is the suffix of the names of files discovered by the test command.
+const testSuffix = "_test.sl"
+
+// testResult is the outcome of a test, or of evaluating a test file if name is empty.
+type testResult struct {
+	name     string
+	duration time.Duration
+	failures []*slang.AssertionError // the assertions that failed
+	err      error                   // the error that stopped the test, or nil
+}
+
+func (r testResult) failed() bool {
+	return len(r.failures) > 0 || r.err != nil
+}
+
+// fileResult is the outcome of the tests of a test file.
+type fileResult struct {
+	filename string
+	duration time.Duration
+	tests    []testResult
+}
+
+func (r fileResult) failed() bool {
+	for _, test := range r.tests {
+		if test.failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// runTest runs the tests defined by deftest in slang test files. Files and directories are named by
+// args; directories are searched recursively for files ending in _test.sl. The current directory is
+// searched if args names none. It returns the exit status: 1 if a test failed or a file could not be
+// evaluated.
+// Usage: `slang test [-v] [-junit file] [files or directories...]`
+func runTest(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: slang test [-v] [-junit file] [files or directories...]")
+		flags.PrintDefaults()
+	}
+	verbose := flags.Bool("v", false, "Print the name of each test as it passes")
+	junit := flags.String("junit", "", "Write a JUnit XML report of the results to `file`")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	filenames, err := testFiles(paths)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if len(filenames) == 0 {
+		fmt.Fprintln(stdout, "no test files")
+		return 0
+	}
+
+	status := 0
+	var results []fileResult
+	for _, filename := range filenames {
+		result := runTestFile(filename, stdin, stdout, stderr)
+		results = append(results, result)
+		printTestResult(stdout, result, *verbose)
+		if result.failed() {
+			status = 1
+		}
+	}
+
+	if *junit != "" {
+		if err := writeJUnit(*junit, results); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// testFiles returns the test files named by paths, searching directories recursively.
+func testFiles(paths []string) ([]string, error) {
+	var filenames []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			filenames = append(filenames, path)
+			continue
+		}
+		err = filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(filename, testSuffix) {
+				filenames = append(filenames, filename)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filenames, nil
+}
+
+// runTestFile evaluates a test file in a new environment and runs the tests it defines, in the
+// order they are defined. Programs write to stdout and stderr through the standard ports.
+func runTestFile(filename string, stdin io.Reader, stdout, stderr io.Writer) fileResult {
+	start := time.Now()
+	result := fileResult{filename: filename}
+
+	env := slang.MakeEnv(nil)
+	setupEnvPorts(&env, 0, nil, stdin, stdout, stderr)
+	if err := evaluateFile(env, filename, nil); err != nil {
+		result.tests = append(result.tests, testResult{err: err})
+		result.duration = time.Since(start)
+		return result
+	}
+
+	var tests []slang.Test
+	for _, symbol := range env.Symbols() {
+		value, _ := env.Get(symbol)
+		if test, isTest := value.(slang.Test); isTest {
+			tests = append(tests, test)
+		}
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		a, _ := tests[i].Source()
+		b, _ := tests[j].Source()
+		return a.Line < b.Line
+	})
+
+	for _, test := range tests {
+		testStart := time.Now()
+		failures, err := test.Run()
+		if err != nil {
+			// other errors are located at the test
+			if src, hasSource := test.Source(); hasSource {
+				err = fmt.Errorf("%s: %s", src, err)
+			}
+		}
+		result.tests = append(result.tests, testResult{
+			name:     string(test.Name),
+			duration: time.Since(testStart),
+			failures: failures,
+			err:      err,
+		})
+	}
+	result.duration = time.Since(start)
+	return result
+}
+
+// printTestResult reports the failures of a test file, followed by a summary line.
+func printTestResult(w io.Writer, result fileResult, verbose bool) {
+	for _, test := range result.tests {
+		switch {
+		case test.name == "":
+			fmt.Fprintf(w, "--- FAIL: %s\n", result.filename)
+			printFailure(w, test.err)
+		case test.failed():
+			fmt.Fprintf(w, "--- FAIL: %s (%.2fs)\n", test.name, test.duration.Seconds())
+			for _, failure := range test.failures {
+				printFailure(w, failure)
+			}
+			if test.err != nil {
+				printFailure(w, test.err)
+			}
+		case verbose:
+			fmt.Fprintf(w, "--- PASS: %s (%.2fs)\n", test.name, test.duration.Seconds())
+		}
+	}
+
+	status := "ok  "
+	if result.failed() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s\t%s\t%.3fs\n", status, result.filename, result.duration.Seconds())
+}
+
+// printFailure prints an error of a test indented under the test name. Failed assertions show the
+// testing contexts and the values compared, with a diff if they span several lines.
+func printFailure(w io.Writer, err error) {
+	failure, isFailure := err.(*slang.AssertionError)
+	if !isFailure {
+		fmt.Fprintf(w, "    %s\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "    %s\n", failure)
+	if len(failure.Contexts) > 0 {
+		fmt.Fprintf(w, "        in: %s\n", strings.Join(failure.Contexts, " > "))
+	}
+	if !failure.Compared {
+		fmt.Fprintf(w, "        actual: %s\n", printer.PrStr(failure.Actual))
+		return
+	}
+
+	expected, actual := prettyValue(failure.Expected), prettyValue(failure.Actual)
+	if !strings.Contains(expected, "\n") && !strings.Contains(actual, "\n") {
+		fmt.Fprintf(w, "        expected: %s\n", expected)
+		fmt.Fprintf(w, "          actual: %s\n", actual)
+		return
+	}
+	for _, line := range splitLines(unifiedDiff("expected", "actual", expected+"\n", actual+"\n")) {
+		fmt.Fprintf(w, "        %s\n", line)
+	}
+}
+
+// prettyValue returns the readable representation of a value, broken over several lines by the
+// formatter if it is long.
+func prettyValue(value slang.LangType) string {
+	s := printer.PrStr(value)
+	formatted, err := format.Config{Width: format.DefaultWidth - 8}.Source("value", s)
+	if err != nil {
+		return s // values like procedures cannot be read back
+	}
+	return strings.TrimSuffix(formatted, "\n")
+}
+
+// JUnit XML report elements.
+type (
+	junitTestsuites struct {
+		XMLName xml.Name         `xml:"testsuites"`
+		Suites  []junitTestsuite `xml:"testsuite"`
+	}
+	junitTestsuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Errors   int             `xml:"errors,attr"`
+		Time     string          `xml:"time,attr"`
+		Cases    []junitTestcase `xml:"testcase"`
+	}
+	junitTestcase struct {
+		Name      string         `xml:"name,attr"`
+		Classname string         `xml:"classname,attr"`
+		Time      string         `xml:"time,attr"`
+		Failures  []junitProblem `xml:"failure"`
+		Error     *junitProblem  `xml:"error,omitempty"`
+	}
+	junitProblem struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// writeJUnit writes the results as a JUnit XML report with a test suite for each file. Each failed
+// assertion is a failure of its test; other errors, like a file that cannot be evaluated, are errors.
+func writeJUnit(filename string, results []fileResult) error {
+	report := junitTestsuites{}
+	for _, result := range results {
+		suite := junitTestsuite{Name: result.filename, Time: seconds(result.duration)}
+		for _, test := range result.tests {
+			testcase := junitTestcase{Name: test.name, Classname: result.filename, Time: seconds(test.duration)}
+			if test.name == "" {
+				testcase.Name = filepath.Base(result.filename)
+			}
+			for _, failure := range test.failures {
+				testcase.Failures = append(testcase.Failures, junitProblem{Message: failure.Error(), Text: details(failure)})
+			}
+			if len(test.failures) > 0 {
+				suite.Failures++
+			}
+			if test.err != nil {
+				testcase.Error = &junitProblem{Message: test.err.Error(), Text: details(test.err)}
+				suite.Errors++
+			}
+			suite.Cases = append(suite.Cases, testcase)
+			suite.Tests++
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append([]byte(xml.Header), append(out, '\n')...), 0644)
+}
+
+// details returns the report of an error printed by printFailure.
+func details(err error) string {
+	var b strings.Builder
+	printFailure(&b, err)
+	return b.String()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
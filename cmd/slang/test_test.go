@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+func TestRunTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slang-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"pass_test.sl": "(deftest adds (is (= 2 (+ 1 1))))\n",
+		"sub/fail_test.sl": "(deftest compares\n  (testing \"lists\"\n    (is (= [1 2] [1 3]))))\n(deftest errors (missing))\n" +
+			"(deftest both\n  (is (= 1 2))\n  (is (= 3 4)))\n",
+		"helper.sl": "(deftest ignored (is false))\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	junit := filepath.Join(dir, "report.xml")
+	status := runTest([]string{"-v", "-junit", junit, dir}, strings.NewReader(""), &stdout, &stderr)
+	if status != 1 {
+		t.Errorf("\n%s:\n\tgot status %d\n\texp 1", "TestRunTest", status)
+	}
+	failPath := filepath.Join(dir, "sub", "fail_test.sl")
+	for _, want := range []string{
+		"--- PASS: adds",
+		"ok  \t" + filepath.Join(dir, "pass_test.sl"),
+		"--- FAIL: compares",
+		failPath + ":3:5: Assertion failed: (= [1 2] [1 3])\n        in: lists\n        expected: [1 2]\n          actual: [1 3]\n",
+		"--- FAIL: errors",
+		failPath + ":4:1: Symbol 'missing' is undefined",
+		"--- FAIL: both (0.00s)\n    " + failPath + ":6:3: Assertion failed: (= 1 2)\n        expected: 1\n          actual: 2\n" +
+			"    " + failPath + ":7:3: Assertion failed: (= 3 4)\n",
+		"FAIL\t" + failPath,
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestRunTest", stdout.String(), want)
+		}
+	}
+	if strings.Contains(stdout.String(), "ignored") {
+		t.Errorf("\n%s:\n\tgot %q\n\texp only files ending in _test.sl", "TestRunTest", stdout.String())
+	}
+
+	data, err := ioutil.ReadFile(junit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestsuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not XML: %s\n%s", err, data)
+	}
+	if len(report.Suites) != 2 || report.Suites[1].Tests != 3 || report.Suites[1].Failures != 2 || report.Suites[1].Errors != 1 ||
+		len(report.Suites[1].Cases[2].Failures) != 2 {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp 2 suites, one with 2 failed tests and an error", "TestRunTest", report)
+	}
+}
+
+func TestPrettyValueDiff(t *testing.T) {
+	long := strings.Repeat("x", 70)
+	var out bytes.Buffer
+	printFailure(&out, testFailure(`["`+long+`" 1]`, `["`+long+`" 2]`))
+	want := "         [\"" + long + "\"\n        - 1]\n        + 2]\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestPrettyValueDiff", out.String(), want)
+	}
+}
+
+// testFailure returns a failed comparison of the values read from expected and actual.
+func testFailure(expected, actual string) *slang.AssertionError {
+	e, _ := parser.Parse("expected", expected)
+	a, _ := parser.Parse("actual", actual)
+	return &slang.AssertionError{Form: slang.Symbol("x"), Compared: true, Expected: e[0], Actual: a[0]}
+}
@@ -0,0 +1,99 @@
+package slang
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Doc returns the documentation of a procedure: its name and parameters followed by its docstring.
+// Usage: `(doc procedure)`
+func Doc(x LangType) (Str, error) {
+	var name Symbol
+	var params Vector
+	var doc string
+
+	switch t := x.(type) {
+	case Lambda:
+		name, params, doc = t.name, t.params, t.doc
+	case Subroutine:
+		name, params, doc = t.Name, t.Params, t.Doc
+	default:
+		return "", fmt.Errorf("%s is not a procedure", x)
+	}
+
+	if name == "" {
+		name = Symbol("lambda")
+	}
+	if params == nil {
+		params = Vector{}
+	}
+	if doc == "" {
+		doc = "No documentation"
+	}
+
+	return Str(fmt.Sprintf("%s %s\n  %s", name, params, doc)), nil
+}
+
+// Arglist returns the parameter Vector of a procedure.
+// Usage: `(arglist procedure)`
+func Arglist(x LangType) (Vector, error) {
+	switch t := x.(type) {
+	case Lambda:
+		return t.params, nil
+	case Subroutine:
+		if t.Params == nil {
+			return nil, fmt.Errorf("No argument list is documented for %s", t.Name)
+		}
+		return t.Params, nil
+	default:
+		return nil, fmt.Errorf("%s is not a procedure", x)
+	}
+}
+
+// Apropos returns a sorted Vector of the special forms and symbols defined in any frame of env
+// whose names contain substr.
+// Usage: `(apropos substr)`
+func Apropos(env *Env, substr string) Vector {
+	seen := map[Symbol]bool{}
+	for _, form := range SpecialForms {
+		seen[form] = true
+	}
+	for frame := env; frame != nil; frame = frame.outer {
+		for _, symbol := range frame.Symbols() {
+			seen[symbol] = true
+		}
+	}
+
+	symbols := make([]Symbol, 0)
+	for symbol := range seen {
+		if strings.Contains(string(symbol), substr) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+
+	matches := make(Vector, len(symbols))
+	for i, symbol := range symbols {
+		matches[i] = symbol
+	}
+	return matches
+}
+
+// SpecialFormDocs documents the usage of each special form.
+var SpecialFormDocs = map[Symbol]string{
+	"are":       "(are [params...] expr args...)\n  Asserts expr like is for each group of args, with the params of expr replaced by the group.",
+	"assert=":   "(assert= expected actual)\n  Asserts that actual is equal to expected.",
+	"begin":     "(begin body...)\n  Evaluates each expression in order and returns the value of the last.",
+	"define":    "(define symbol value) or (define symbol [params...] docstring? body...)\n  Defines symbol in the current environment.",
+	"deftest":   "(deftest name body...)\n  Defines a test run by `slang test`.",
+	"for-all":   "(for-all [symbol generator ...] body...)\n  Asserts that body does not return false for random values of the generators, and shrinks counterexamples.",
+	"if":        "(if predicate consequent alternative?)\n  Evaluates consequent if predicate is true, otherwise alternative.",
+	"is":        "(is expr message?)\n  Asserts that expr is true. Failures stop the test and are reported with the values compared.",
+	"lambda":    "(lambda [params...] docstring? body...)\n  Makes a procedure that binds its arguments to params and evaluates body.",
+	"quote":     "(quote x) or 'x\n  Returns x without evaluating it.",
+	"testing":   "(testing description body...)\n  Evaluates body, adding description to the assertions that fail in it.",
+	"trace":     "(trace procedure...)\n  Prints each application of the named procedures and its result to *err*.",
+	"untrace":   "(untrace procedure...)\n  Stops tracing the named procedures.",
+	"with-open": "(with-open [name port] body...)\n  Binds name to port, evaluates body and closes port.",
+}
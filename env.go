@@ -2,65 +2,164 @@ package slang
 
 import (
 	"fmt"
+	"sort"
 )
 
-// Env environment with scopes and reference to enclosing frame
+// Env environment with scopes and reference to enclosing frame. The frame of a procedure application
+// made by analyzed code holds its parameters and local definitions in slots addressed by index; other
+// symbols are defined in a map.
 type Env struct {
-	outer *Env
-	frame map[Symbol]LangType
+	outer    *Env
+	frame    map[Symbol]LangType
+	names    []Symbol   // symbols of the slots
+	slots    []LangType // values of the slots; unbound until the symbol is defined
+	packages map[string][]Symbol
+	hooks    *hookState
+	tests    *testState
 }
 
-// Get performs a symbol lookup. If the symbol key is not present in the current
-// or any enclosing frame, an undefined symbol error is returned.
-func (env *Env) Get(symbol Symbol) (LangType, error) {
-	if value, exists := env.frame[symbol]; exists {
-		return value, nil
+// unbound is the value of a slot before its definition is evaluated.
+type unbound struct{}
+
+// Outer returns the enclosing environment, or nil if env is the outermost environment.
+func (env *Env) Outer() *Env {
+	return env.outer
+}
+
+// Symbols returns the sorted symbols defined in the current frame. Symbols of enclosing frames are
+// not included; use Outer to walk the enclosing environments.
+func (env *Env) Symbols() []Symbol {
+	symbols := make([]Symbol, 0, len(env.names)+len(env.frame))
+	for i, symbol := range env.names {
+		if _, isUnbound := env.slots[i].(unbound); !isUnbound {
+			symbols = append(symbols, symbol)
+		}
 	}
+	for symbol := range env.frame {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	return symbols
+}
 
-	if env.outer != nil {
-		return env.outer.Get(symbol)
+// Packages returns a map of subroutine package names loaded into the current frame to the symbols
+// each package defined.
+func (env *Env) Packages() map[string][]Symbol {
+	return env.packages
+}
+
+func (env *Env) String() string {
+	return "<environment>"
+}
+
+// slot returns the index of the slot of symbol in the current frame, or -1. The names are scanned;
+// analyzed code addresses its local variables by index, so the scan is only made when a symbol is
+// looked up by name, as by the testing forms and the evaluation of hooked runs.
+func (env *Env) slot(symbol Symbol) int {
+	for i, name := range env.names {
+		if name == symbol {
+			return i
+		}
 	}
+	return -1
+}
 
+// lookup returns the value of symbol in the current frame, without enclosing frames.
+func (env *Env) lookup(symbol Symbol) (LangType, bool) {
+	if i := env.slot(symbol); i >= 0 {
+		if _, isUnbound := env.slots[i].(unbound); !isUnbound {
+			return env.slots[i], true
+		}
+	}
+	value, exists := env.frame[symbol]
+	return value, exists
+}
+
+// Get performs a symbol lookup. If the symbol key is not present in the current
+// or any enclosing frame, an undefined symbol error is returned.
+func (env *Env) Get(symbol Symbol) (LangType, error) {
+	for scope := env; scope != nil; scope = scope.outer {
+		if value, exists := scope.lookup(symbol); exists {
+			return value, nil
+		}
+	}
 	return nil, fmt.Errorf("Symbol '%s' is undefined", symbol)
 }
 
 // Define adds a new symbol definition to the environment. If symbol is already defined, an error is
 // returned; use the Mutate method to change the definition of a symbol.
 func (env *Env) Define(symbol Symbol, value LangType) error {
-	if _, exists := env.frame[symbol]; !exists {
-		env.frame[symbol] = value
+	if _, exists := env.lookup(symbol); exists {
+		return fmt.Errorf("Symbol '%s' is already defined", symbol)
+	}
+	if i := env.slot(symbol); i >= 0 {
+		env.slots[i] = value
 		return nil
 	}
-	return fmt.Errorf("Symbol '%s' is already defined", symbol)
+	env.frame[symbol] = value
+	return nil
 }
 
 // Mutate mutates a symbol definition. Mutating an undefined symbol is not allowed; use the Set
 // method to add a new symbol definition.
 func (env *Env) Mutate(symbol Symbol, value LangType) error {
-	if _, exists := env.frame[symbol]; exists {
-		env.frame[symbol] = value
+	if _, exists := env.lookup(symbol); !exists {
+		return fmt.Errorf("Symbol '%s' is undefined", symbol)
+	}
+	if i := env.slot(symbol); i >= 0 {
+		env.slots[i] = value
 		return nil
 	}
-	return fmt.Errorf("Symbol '%s' is undefined", symbol)
+	env.frame[symbol] = value
+	return nil
 }
 
-// UseSubrPackage loads a
+// UseSubrPackage loads a package of subroutines into the current frame. Each key of pkg is defined
+// as a symbol bound to its Subroutine, documented by the entry of the same key in docs. docs may be
+// nil. The package name is recorded and listed by Packages.
 func (env *Env) UseSubrPackage(pkgName string,
-	pkg map[string]func(...LangType) (LangType, error)) error {
+	pkg map[string]func(...LangType) (LangType, error), docs map[string]SubrDoc) error {
 
+	symbols := make([]Symbol, 0, len(pkg))
 	for k, v := range pkg {
-		if err := env.Define(Symbol(k), Subroutine{v}); err != nil {
+		subr := Subroutine{Func: v, Name: Symbol(k), SubrDoc: docs[k]}
+		if err := env.Define(Symbol(k), subr); err != nil {
 			return err
 		}
+		symbols = append(symbols, Symbol(k))
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	if env.packages == nil {
+		env.packages = map[string][]Symbol{}
 	}
+	env.packages[pkgName] = symbols
 	return nil
 }
 
 // MakeEnv constructs an empty environment.
 func MakeEnv(outer *Env) Env {
 	frame := map[Symbol]LangType{}
+	hooks, tests := &hookState{}, &testState{}
+	if outer != nil {
+		hooks, tests = outer.hooks, outer.tests
+	}
 	return Env{
-		outer: outer,
-		frame: frame,
+		outer:    outer,
+		frame:    frame,
+		packages: map[string][]Symbol{},
+		hooks:    hooks,
+		tests:    tests,
+	}
+}
+
+// makeFrame constructs an environment for an application of an analyzed procedure with a slot for
+// each of names. The slots are bound to args, and the rest are unbound.
+func makeFrame(outer *Env, names []Symbol, args []LangType) Env {
+	slots := make([]LangType, len(names))
+	copy(slots, args)
+	for i := len(args); i < len(slots); i++ {
+		slots[i] = unbound{}
 	}
+	return Env{outer: outer, frame: map[Symbol]LangType{}, names: names, slots: slots, hooks: outer.hooks,
+		tests: outer.tests}
 }
@@ -0,0 +1,103 @@
+package slang
+
+import (
+	"testing"
+)
+
+func TestEnvSymbols(t *testing.T) {
+	global := MakeEnv(nil)
+	global.Define(Symbol("b"), Number(2))
+	global.Define(Symbol("a"), Number(1))
+	local := MakeEnv(&global)
+	local.Define(Symbol("c"), Number(3))
+
+	cases := []struct {
+		env  *Env
+		want []Symbol
+	}{
+		{&global, []Symbol{"a", "b"}},
+		{&local, []Symbol{"c"}},
+		{local.Outer(), []Symbol{"a", "b"}},
+	}
+
+	for _, c := range cases {
+		got := c.env.Symbols()
+		if !Eq(symbolVector(got), symbolVector(c.want)) {
+			t.Errorf("Symbols() == %v, want %v", got, c.want)
+		}
+	}
+
+	if global.Outer() != nil {
+		t.Errorf("Outer() of global environment == %v, want nil", global.Outer())
+	}
+}
+
+func TestEnvPackages(t *testing.T) {
+	env := MakeEnv(nil)
+	pkg := map[string]func(...LangType) (LangType, error){
+		"two": func(args ...LangType) (LangType, error) { return Number(2), nil },
+		"one": func(args ...LangType) (LangType, error) { return Number(1), nil },
+	}
+	if err := env.UseSubrPackage("nums", pkg, nil); err != nil {
+		t.Fatalf("UseSubrPackage returned unexpected error %s", err)
+	}
+
+	got := env.Packages()["nums"]
+	want := []Symbol{"one", "two"}
+	if !Eq(symbolVector(got), symbolVector(want)) {
+		t.Errorf("Packages()[\"nums\"] == %v, want %v", got, want)
+	}
+}
+
+func TestEnvSlots(t *testing.T) {
+	global := MakeEnv(nil)
+	global.Define(Symbol("a"), Number(1))
+	frame := makeFrame(&global, []Symbol{"x", "y"}, []LangType{Number(2)})
+
+	if got := frame.Symbols(); !Eq(symbolVector(got), symbolVector([]Symbol{"x"})) {
+		t.Errorf("Symbols() == %v, want [x]", got)
+	}
+	if err := frame.Mutate(Symbol("y"), Number(0)); err == nil {
+		t.Errorf("Mutate(y) of unbound slot returned no error")
+	}
+	if err := frame.Define(Symbol("x"), Number(0)); err == nil {
+		t.Errorf("Define(x) of bound slot returned no error")
+	}
+	frame.Define(Symbol("y"), Number(3))
+	frame.Define(Symbol("z"), Number(4))
+	frame.Mutate(Symbol("x"), Number(5))
+
+	cases := []struct {
+		symbol Symbol
+		want   LangType
+	}{
+		{"a", Number(1)},
+		{"x", Number(5)},
+		{"y", Number(3)},
+		{"z", Number(4)},
+	}
+	for _, c := range cases {
+		if got, err := frame.Get(c.symbol); err != nil || got != c.want {
+			t.Errorf("Get(%s) == %v, %v, want %v", c.symbol, got, err, c.want)
+		}
+	}
+	if got := frame.Symbols(); !Eq(symbolVector(got), symbolVector([]Symbol{"x", "y", "z"})) {
+		t.Errorf("Symbols() == %v, want [x y z]", got)
+	}
+
+	// copies of a frame share the map of its definitions
+	copied := makeFrame(&global, nil, nil)
+	definer := copied
+	definer.Define(Symbol("w"), Number(6))
+	if got, err := copied.Get(Symbol("w")); err != nil || got != Number(6) {
+		t.Errorf("Get(w) == %v, %v, want 6", got, err)
+	}
+}
+
+func symbolVector(symbols []Symbol) Vector {
+	vec := make(Vector, len(symbols))
+	for i, symbol := range symbols {
+		vec[i] = symbol
+	}
+	return vec
+}
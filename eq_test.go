@@ -0,0 +1,42 @@
+package slang_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/gen"
+)
+
+func TestEqReflexive(t *testing.T) {
+	reflexive := func(v gen.Value) bool {
+		return slang.Eq(v.LangType, v.LangType)
+	}
+	if err := quick.Check(reflexive, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEqSymmetric(t *testing.T) {
+	symmetric := func(a, b gen.Value) bool {
+		return slang.Eq(a.LangType, b.LangType) == slang.Eq(b.LangType, a.LangType)
+	}
+	if err := quick.Check(symmetric, nil); err != nil {
+		t.Error(err)
+	}
+	// shrunk values are often equal to each other
+	shrunk := func(v gen.Value) bool {
+		candidates := gen.Any().Shrink(v.LangType)
+		for _, a := range candidates {
+			for _, b := range candidates {
+				if slang.Eq(a, b) != slang.Eq(b, a) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(shrunk, &quick.Config{MaxCount: 20}); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,8 +2,14 @@ package slang
 
 import (
 	"fmt"
+	"io"
 )
 
+// SpecialForms are the symbols the evaluator treats as special forms rather than procedure
+// applications.
+var SpecialForms = []Symbol{"are", "assert=", "begin", "define", "deftest", "for-all", "if", "is", "lambda", "quote",
+	"testing", "trace", "untrace", "with-open"}
+
 func evaluateListItems(lst List, env Env) ([]LangType, error) {
 	lstLen := int(lst.Len())
 	values := make([]LangType, lstLen)
@@ -33,6 +39,19 @@ func evaluateVectorItems(vec Vector, env Env) (Vector, error) {
 	return values, nil
 }
 
+// evaluateMapValues evaluates the values of a map literal. Keys are literals and are not evaluated.
+func evaluateMapValues(m Map, env Env) (Map, error) {
+	values := make([]LangType, len(m.values))
+	for i, item := range m.values {
+		value, err := Evaluate(item, env)
+		if err != nil {
+			return Map{}, err
+		}
+		values[i] = value
+	}
+	return Map{keys: m.keys, values: values, index: m.index}, nil
+}
+
 // evaluateSequence evaluates each expression in the given list except the last expression. The tail
 // expression is returned to be evaluated.
 func evaluateBodyTCO(lst List, env Env) (LangType, error) {
@@ -52,6 +71,8 @@ func evaluateExpr(expr LangType, env Env) (LangType, error) {
 	switch t := expr.(type) {
 	case Vector:
 		return evaluateVectorItems(t, env)
+	case Map:
+		return evaluateMapValues(t, env)
 	case Symbol:
 		return env.Get(t)
 	default:
@@ -59,9 +80,32 @@ func evaluateExpr(expr LangType, env Env) (LangType, error) {
 	}
 }
 
-// Evaluate evaluates an expression
+// Evaluate evaluates an expression. The expression is analyzed into a tree of Go closures, and the
+// bodies of the procedures it makes are analyzed with it; use Analyze to evaluate an expression many
+// times without analyzing it again. If env has a Hook, the expression is evaluated by walking it
+// instead, so the Hook observes every form. Hooked evaluations, like those of a debugger or
+// profiler, therefore run on a different evaluator than unhooked ones.
 func Evaluate(expr LangType, env Env) (LangType, error) {
+	if env.hooks != nil && env.hooks.hook != nil {
+		return evaluateHooked(expr, env)
+	}
+	return analyze(expr, false, nil)(&env)
+}
+
+// evaluate walks and evaluates an expression. hooks is nil unless the evaluation is observed by a
+// Hook.
+func evaluate(expr LangType, env Env, hooks *hookState) (LangType, error) {
+	applied := false // a procedure was applied; later applications are tail calls
 	for {
+		if hooks != nil && hooks.hook == nil {
+			hooks = nil // the hook was removed during evaluation
+		}
+		if hooks != nil {
+			if err := hooks.hook.Eval(expr, env, hooks.stack); err != nil {
+				return nil, err
+			}
+		}
+
 		form, isList := expr.(List)
 		if !isList {
 			return evaluateExpr(expr, env)
@@ -104,7 +148,7 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 			var err error
 			if operands.Len() >= 3 {
 				// syntactic sugar for a procedure definition
-				// Usage: `(define <procedureName> [params...] body...)
+				// Usage: `(define <procedureName> [params...] docstring? body...)
 				procdef := operands.Rest()
 				params := procdef.First()
 				body := procdef.Rest().(List)
@@ -114,7 +158,10 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 					return nil, fmt.Errorf("Second argument must be a vector for procedure definition")
 				}
 
-				defval, err = MakeLambda(env, paramsVec, body)
+				var lambda Lambda
+				lambda, err = MakeLambda(env, paramsVec, body)
+				lambda.source = form.source
+				defval = lambda
 			} else {
 				defval, err = Evaluate(operands.Nth(1), env)
 			}
@@ -123,6 +170,9 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 				return nil, err
 			}
 
+			// name anonymous lambdas after the symbol they are defined as
+			defval = named(defval, defsym)
+
 			env.Define(defsym, defval)
 
 			return defval, nil
@@ -141,7 +191,12 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 
 			body := operands.Rest().(List)
 
-			return MakeLambda(env, params, body)
+			lambda, err := MakeLambda(env, params, body)
+			if err != nil {
+				return nil, err
+			}
+			lambda.source = form.source
+			return lambda, nil
 		case "quote":
 			operands := form.Rest()
 
@@ -149,6 +204,63 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 				return nil, fmt.Errorf("Invalid number of arguments - expected 1 argument")
 			}
 			return operands.First(), nil
+		case "trace", "untrace":
+			return evaluateTrace(form, env)
+		case "deftest":
+			return evaluateDeftest(form, env)
+		case "testing":
+			return evaluateTesting(form, env)
+		case "is":
+			return evaluateIs(form, env)
+		case "assert=":
+			return evaluateAssertEq(form, env)
+		case "are":
+			return evaluateAre(form, env)
+		case "for-all":
+			return evaluateForAll(form, env)
+		case "with-open":
+			operands := form.Rest()
+
+			// Usage: `(with-open [name port] body...)`
+			if operands.Len() < 2 {
+				return nil, fmt.Errorf("Invalid form for with-open")
+			}
+
+			binding, isVec := operands.First().(Vector)
+			if !isVec || binding.Len() != 2 {
+				return nil, fmt.Errorf("First argument to with-open must be a vector of a symbol and a port")
+			}
+			name, isSymbol := binding[0].(Symbol)
+			if !isSymbol {
+				return nil, fmt.Errorf("First argument to with-open must be a vector of a symbol and a port")
+			}
+
+			value, err := Evaluate(binding[1], env)
+			if err != nil {
+				return nil, err
+			}
+			closer, isCloser := value.(io.Closer)
+			if !isCloser {
+				return nil, fmt.Errorf("%s cannot be closed", value)
+			}
+
+			// the body is not evaluated in tail position; the port is closed after evaluation
+			scope := MakeEnv(&env)
+			scope.Define(name, value)
+			var result LangType
+			for _, expr := range operands.Rest().(List).Items() {
+				if result, err = Evaluate(expr, scope); err != nil {
+					break
+				}
+			}
+
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return nil, err
+			}
+			return result, nil
 		// Tail-call optimized paths
 		case "begin":
 			operands := form.Rest()
@@ -157,9 +269,7 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 				return nil, fmt.Errorf("Invalid form for begin")
 			}
 
-			body := operands.Rest().(List)
-
-			tail, err := evaluateBodyTCO(body, env)
+			tail, err := evaluateBodyTCO(operands.(List), env)
 			if err != nil {
 				return nil, err
 			}
@@ -218,6 +328,23 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 					env.Define(bindSymbol, bindValue)
 				}
 
+				if hooks != nil {
+					if err := hooks.enter(Frame{lambda, args, form, env}, applied); err != nil {
+						return nil, err
+					}
+				}
+				applied = true
+
+				if lambda.traced {
+					return traceApplication(lambda.name, args, env, func() (LangType, error) {
+						tail, err := evaluateBodyTCO(lambda.body, env)
+						if err != nil {
+							return nil, err
+						}
+						return Evaluate(tail, env)
+					})
+				}
+
 				// evaluate all items in body except the final expression
 				// last expression is set in for next TCO loop iteration to evaluate it
 				expr, err = evaluateBodyTCO(lambda.body, env)
@@ -225,6 +352,16 @@ func Evaluate(expr LangType, env Env) (LangType, error) {
 					return nil, err
 				}
 			} else if subr, isSubroutine := procedure.(Subroutine); isSubroutine {
+				if hooks != nil {
+					if err := hooks.enter(Frame{subr, args, form, env}, applied); err != nil {
+						return nil, err
+					}
+				}
+				if subr.traced {
+					return traceApplication(subr.Name, args, env, func() (LangType, error) {
+						return subr.Apply(args...)
+					})
+				}
 				return subr.Apply(args...)
 			} else {
 				return nil, fmt.Errorf("'%s' is not applicable", procedure)
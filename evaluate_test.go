@@ -0,0 +1,269 @@
+package slang_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// evaluateAll parses and evaluates input in env, returning the value of the last form.
+func evaluateAll(t *testing.T, input string, env slang.Env) slang.LangType {
+	t.Helper()
+	exprs, err := parser.Parse(t.Name(), input)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned unexpected error %s", input, err)
+	}
+	var result slang.LangType
+	for _, expr := range exprs {
+		result, err = slang.Evaluate(expr, env)
+		if err != nil {
+			t.Fatalf("Evaluate(%s) returned unexpected error %s", expr, err)
+		}
+	}
+	return result
+}
+
+var docTests = []struct {
+	input string
+	want  slang.Str
+}{
+	{"(define f [x] \"Returns x.\" x) (doc f)", "f [x]\n  Returns x."},
+	{"(define f [x] x) (doc f)", "f [x]\n  No documentation"},
+	{"(define f (lambda [a b] \"Adds.\" a)) (doc f)", "f [a b]\n  Adds."},
+	{"(doc (lambda [] \"Anonymous.\" 1))", "lambda []\n  Anonymous."},
+	{"(doc (lambda [] \"Only a value.\"))", "lambda []\n  No documentation"},
+}
+
+func TestDocstrings(t *testing.T) {
+	for _, test := range docTests {
+		env := slang.MakeEnv(nil)
+		env.Define(slang.Symbol("doc"), slang.Subroutine{
+			Func: func(args ...slang.LangType) (slang.LangType, error) { return slang.Doc(args[0]) },
+		})
+		got := evaluateAll(t, test.input, env)
+		if got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestDocstringIsNotEvaluated(t *testing.T) {
+	got := evaluateAll(t, "(define f [] \"Returns one.\" 1) (f)", slang.MakeEnv(nil))
+	if got != slang.Number(1) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp 1", "TestDocstringIsNotEvaluated", got)
+	}
+	got = evaluateAll(t, "(define g [] \"A value.\") (g)", slang.MakeEnv(nil))
+	if got != slang.Str("A value.") {
+		t.Errorf("\n%s:\n\tgot %v\n\texp \"A value.\"", "TestDocstringIsNotEvaluated", got)
+	}
+}
+
+// closeRecorder counts the times it is closed.
+type closeRecorder struct {
+	closed int
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestWithOpen(t *testing.T) {
+	cases := []struct {
+		input string
+		want  slang.LangType
+		err   bool
+	}{
+		{"(with-open [c resource] 1 c 2)", slang.Number(2), false},
+		{"(with-open [c resource] undefined-symbol)", nil, true},
+	}
+
+	for _, c := range cases {
+		recorder := &closeRecorder{}
+		env := slang.MakeEnv(nil)
+		env.Define(slang.Symbol("resource"), recorder)
+
+		exprs, _ := parser.Parse("TestWithOpen", c.input)
+		got, err := slang.Evaluate(exprs[0], env)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("\n%s:\n\tgot %v, %v\n\texp %v", c.input, got, err, c.want)
+		}
+		if recorder.closed != 1 {
+			t.Errorf("\n%s:\n\tclosed %d times\n\texp 1", c.input, recorder.closed)
+		}
+	}
+}
+
+func TestMapLiteral(t *testing.T) {
+	env := slang.MakeEnv(nil)
+	env.Define(slang.Symbol("x"), slang.Number(2))
+	got := evaluateAll(t, "{x x \"y\" [x]}", env)
+	if want := `{x 2 "y" [2]}`; got.(slang.Map).String() != want {
+		t.Errorf("\n%s:\n\tgot %v\n\texp %s", "TestMapLiteral", got, want)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	env := slang.MakeEnv(nil)
+	var traced bytes.Buffer
+	env.UsePorts(strings.NewReader(""), &bytes.Buffer{}, &traced)
+	env.Define(slang.Symbol("inc"), slang.Subroutine{Name: "inc",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return args[0].(slang.Number) + 1, nil },
+	})
+	env.Define(slang.Symbol("="), slang.Subroutine{Name: "=",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return args[0] == args[1], nil },
+	})
+
+	got := evaluateAll(t, `(define count [n] (if (= n 2) n (count (inc n))))
+		(trace count inc)
+		(count 0)
+		(untrace inc)
+		(count 1)`, env)
+	if got != slang.Number(2) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp 2", "TestTrace", got)
+	}
+	want := `> (count 0)
+  > (inc 0)
+  < 1
+  > (count 1)
+    > (inc 1)
+    < 2
+    > (count 2)
+    < 2
+  < 2
+< 2
+> (count 1)
+  > (count 2)
+  < 2
+< 2
+`
+	if traced.String() != want {
+		t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestTrace", traced.String(), want)
+	}
+
+	exprs, _ := parser.Parse("TestTrace", "(trace 1) (trace undefined) (trace *err*)")
+	for _, expr := range exprs {
+		if _, err := slang.Evaluate(expr, env); err == nil {
+			t.Errorf("\n%s:\n\tgot no error\n\texp an error", expr)
+		}
+	}
+}
+
+func TestLambdaSource(t *testing.T) {
+	env := slang.MakeEnv(nil)
+	evaluateAll(t, "(define f [x] x)\n(define g (lambda [x] x))", env)
+	for _, c := range []struct {
+		name slang.Symbol
+		want string
+	}{{"f", "TestLambdaSource:1:1"}, {"g", "TestLambdaSource:2:11"}} {
+		f, _ := env.Get(c.name)
+		src, hasSource := f.(slang.Lambda).Source()
+		if !hasSource || src.String() != c.want {
+			t.Errorf("\n%s:\n\tgot %v %t\n\texp %s", c.name, src, hasSource, c.want)
+		}
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string // error, contexts, expected and actual; "" if the assertion passes
+	}{
+		{"(is (= 1 (+ 0 1)))", ""},
+		{"(is true \"message\")", ""},
+		{"(are [x y] (= x y) 1 1 2 2)", ""},
+		{"(assert= [1 2] [1 2])", ""},
+		{"(is (= 1 (+ 1 1)))", "TestAssertions:1:1: Assertion failed: (= 1 (+ 1 1)) [] 1 2"},
+		{"(is false \"message\")", "TestAssertions:1:1: Assertion failed: false - message [] <nil> false"},
+		{"(is (eq? 1 (+ 1 1)))", "TestAssertions:1:1: Assertion failed: (eq? 1 (+ 1 1)) [] <nil> (not (eq? 1 2))"},
+		{"(assert= [1 2] [1 3])", "TestAssertions:1:1: Assertion failed: (assert= [1 2] [1 3]) [] [1 2] [1 3]"},
+		{"(are [x y] (= x y) 1 1 2 (+ 1 2))", "TestAssertions:1:1: Assertion failed: (= 2 (+ 1 2)) [] 2 3"},
+		{"(are [x] (= (quote x) x) 1)", "TestAssertions:1:1: Assertion failed: (= (quote x) 1) [] x 1"},
+		{"(are [x] (= 2 ((lambda [x] x) 2)) 1)", ""},
+		{"(are [x] (= 2 (begin (define f [x] x) (f 2))) 1)", ""},
+		{"(are [x] (= {\"k\" 1} {\"k\" x}) 2)", "TestAssertions:1:1: Assertion failed: (= {\"k\" 1} {\"k\" 2}) [] {\"k\" 1} {\"k\" 2}"},
+		{"(testing \"a\" (testing \"b\" 1 (is (= 1 2))))", "TestAssertions:1:29: Assertion failed: (= 1 2) [a b] 1 2"},
+	}
+
+	for _, c := range cases {
+		env := slang.MakeEnv(nil)
+		for _, name := range []string{"=", "eq?"} {
+			env.Define(slang.Symbol(name), slang.Subroutine{Name: slang.Symbol(name),
+				Func: func(args ...slang.LangType) (slang.LangType, error) { return slang.Eq(args[0], args[1]), nil },
+			})
+		}
+		env.Define(slang.Symbol("+"), slang.Subroutine{Name: "+",
+			Func: func(args ...slang.LangType) (slang.LangType, error) {
+				return args[0].(slang.Number) + args[1].(slang.Number), nil
+			},
+		})
+
+		exprs, _ := parser.Parse("TestAssertions", c.input)
+		_, err := slang.Evaluate(exprs[0], env)
+		got := ""
+		if failure, isFailure := err.(*slang.AssertionError); isFailure {
+			got = fmt.Sprintf("%s %v %v %v", failure, failure.Contexts, failure.Expected, failure.Actual)
+		} else if err != nil {
+			got = err.Error()
+		}
+		if got != c.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", c.input, got, c.want)
+		}
+	}
+}
+
+func TestDeftest(t *testing.T) {
+	env := slang.MakeEnv(nil)
+	env.Define(slang.Symbol("="), slang.Subroutine{Name: "=",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return slang.Eq(args[0], args[1]), nil },
+	})
+	evaluateAll(t, "(define x 1)\n(deftest passes (define y x) (is true))\n(deftest fails (is false))\n"+
+		"(deftest continues (is false) (testing \"t\" (is (= 1 2)) (is true)) (are [a] a true false 0) (missing) (is false))\n"+
+		"(define check [v] (is v))\n(deftest helper (check false) (testing \"h\" (check 0)))", env)
+
+	for _, c := range []struct {
+		name     string
+		failures string
+		err      bool
+	}{
+		{"passes", "", false},
+		{"fails", "false []", false},
+		{"continues", "false [] (= 1 2) [t] false [] 0 []", true},
+		{"helper", "v [] v [h]", false},
+	} {
+		value, _ := env.Get(slang.Symbol(c.name))
+		test, isTest := value.(slang.Test)
+		if !isTest {
+			t.Fatalf("\n%s:\n\tgot %v\n\texp a test", c.name, value)
+		}
+		failures, err := test.Run()
+		var got []string
+		for _, failure := range failures {
+			got = append(got, fmt.Sprintf("%v %v", failure.Form, failure.Contexts))
+		}
+		if strings.Join(got, " ") != c.failures || (err != nil) != c.err {
+			t.Errorf("\n%s:\n\tgot %q %v\n\texp %q, error %t", c.name, got, err, c.failures, c.err)
+		}
+	}
+	if _, err := env.Get(slang.Symbol("y")); err == nil {
+		t.Errorf("\n%s:\n\tgot y defined globally\n\texp definitions local to the test", "TestDeftest")
+	}
+}
+
+func TestWalkBegin(t *testing.T) {
+	// the tree walker evaluates every expression of begin, the first included
+	for _, test := range []struct{ program, want string }{
+		{"(begin 1)", "1"},
+		{"(begin 1 2 3)", "3"},
+		{"(begin (define x 1) x)", "1"},
+		{"(define f [] (begin (define y 2) 3) y) (f)", "2"},
+	} {
+		if got := run(t, test.program, slang.Walk); got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.program, got, test.want)
+		}
+	}
+}
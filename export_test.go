@@ -0,0 +1,15 @@
+package slang
+
+// Walk evaluates an expression with the tree-walking evaluator, which Evaluate uses when a Hook is
+// set, for comparisons with the analyzing evaluator.
+func Walk(expr LangType, env Env) (LangType, error) {
+	return evaluate(expr, env, nil)
+}
+
+// MapFrames evaluates an expression like Evaluate, but the procedures it analyzes define their
+// parameters and local definitions in the map of their frames instead of giving them slots.
+func MapFrames(expr LangType, env Env) (LangType, error) {
+	slotFrames = false
+	defer func() { slotFrames = true }()
+	return Evaluate(expr, env)
+}
@@ -0,0 +1,144 @@
+package slang
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Generator makes random values for for-all. The gen package implements Generators for every type
+// of slang value.
+type Generator interface {
+	// Generate returns a random value. size bounds the magnitude of numbers and the length of
+	// strings and collections; it grows as for-all runs more trials.
+	Generate(r *rand.Rand, size int) LangType
+	// Shrink returns values smaller than value, simplest first, to search for a simpler
+	// counterexample of a failed property.
+	Shrink(value LangType) []LangType
+}
+
+// Limits of for-all.
+const (
+	ForAllTrials  = 100  // number of random trials of a property
+	maxShrinkRuns = 1000 // evaluations of a property while shrinking a counterexample
+)
+
+// property is a for-all form: symbols bound to generators and a body that must hold for all values.
+type property struct {
+	symbols    []Symbol
+	generators []Generator
+	body       List
+	env        Env
+}
+
+// holds evaluates the body of the property with the symbols bound to values. A property fails if its
+// body returns false or an error; the error is returned, or nil if the property holds.
+func (prop property) holds(values []LangType) (bool, error) {
+	scope := MakeEnv(&prop.env)
+	for i, symbol := range prop.symbols {
+		scope.Define(symbol, values[i])
+	}
+	var result LangType
+	var err error
+	for _, expr := range prop.body.Items() {
+		if result, err = Evaluate(expr, scope); err != nil {
+			return false, err
+		}
+	}
+	return result != false, nil
+}
+
+// shrink searches for simpler values for which the property fails, by shrinking one value at a
+// time. It returns the simplest failing values found, the number of successful shrinks and the error
+// of the property for the values.
+func (prop property) shrink(values []LangType, err error) ([]LangType, int, error) {
+	shrinks, runs := 0, 0
+	for shrunk := true; shrunk && runs < maxShrinkRuns; {
+		shrunk = false
+		for i := 0; i < len(values) && !shrunk; i++ {
+			for _, candidate := range prop.generators[i].Shrink(values[i]) {
+				if runs++; runs > maxShrinkRuns {
+					break
+				}
+				trial := append([]LangType{}, values...)
+				trial[i] = candidate
+				if holds, trialErr := prop.holds(trial); !holds {
+					values, err, shrunk = trial, trialErr, true
+					shrinks++
+					break
+				}
+			}
+		}
+	}
+	return values, shrinks, err
+}
+
+// evaluateForAll checks that a property holds for random values of generators. Each symbol is bound
+// to a value of the generator following it and the body is evaluated, ForAllTrials times. If the body
+// returns false or an error, the values are shrunk to a simpler counterexample, which is reported in
+// an *AssertionError.
+// Usage: `(for-all [symbol generator ...] body...)`
+func evaluateForAll(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() < 2 {
+		return nil, fmt.Errorf("Invalid form for for-all")
+	}
+	bindings, isVec := operands.First().(Vector)
+	if !isVec || len(bindings)%2 != 0 {
+		return nil, fmt.Errorf("First argument to for-all must be a vector of symbols and generators")
+	}
+
+	prop := property{body: operands.Rest().(List), env: env}
+	for i := 0; i < len(bindings); i += 2 {
+		symbol, isSymbol := bindings[i].(Symbol)
+		if !isSymbol {
+			return nil, fmt.Errorf("First argument to for-all must be a vector of symbols and generators")
+		}
+		value, err := Evaluate(bindings[i+1], env)
+		if err != nil {
+			return nil, err
+		}
+		generator, isGenerator := value.(Generator)
+		if !isGenerator {
+			return nil, fmt.Errorf("'%s' is not a generator", bindings[i+1])
+		}
+		prop.symbols = append(prop.symbols, symbol)
+		prop.generators = append(prop.generators, generator)
+	}
+
+	// the assertions of the body fail the property; they are not collected by a running Test
+	var run *testRun
+	if env.tests != nil && env.tests.run != nil {
+		run, env.tests.run = env.tests.run, nil
+		defer func() { env.tests.run = run }()
+	}
+
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	for trial := 1; trial <= ForAllTrials; trial++ {
+		values := make([]LangType, len(prop.generators))
+		for i, generator := range prop.generators {
+			values[i] = generator.Generate(r, trial)
+		}
+		holds, err := prop.holds(values)
+		if holds {
+			continue
+		}
+
+		values, shrinks, err := prop.shrink(values, err)
+		counterexample := Vector{}
+		for i, symbol := range prop.symbols {
+			counterexample = append(counterexample, symbol, values[i])
+		}
+		message := fmt.Sprintf("Falsified after %d trials and %d shrinks with seed %d", trial, shrinks, seed)
+		if err != nil {
+			message += ": " + err.Error()
+		}
+		if run != nil {
+			env.tests.run = run
+		}
+		src, _ := form.Source()
+		return fail(env, &AssertionError{Source: src, Form: form, Message: message, Actual: counterexample})
+	}
+	return true, nil
+}
@@ -0,0 +1,290 @@
+// Package format prints slang source in a canonical style. Forms that fit within the line width are
+// printed on one line; longer forms are broken with one element per line, indented by rules that
+// depend on the kind of form. Comments are kept, and at most one blank line is kept between forms.
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// DefaultWidth is the line width used by Source.
+const DefaultWidth = 80
+
+// blockForms are special forms whose body is indented by two spaces rather than aligned with their
+// first argument. The value is the number of arguments kept on the line of the operator.
+var blockForms = map[string]int{
+	"begin":     0,
+	"define":    1,
+	"deftest":   1,
+	"for-all":   1,
+	"if":        1,
+	"lambda":    1,
+	"testing":   1,
+	"with-open": 1,
+}
+
+// Config controls formatting.
+type Config struct {
+	Width int // preferred maximum line width in runes
+}
+
+// Source formats slang source with the default configuration.
+func Source(name, input string) (string, error) {
+	return Config{Width: DefaultWidth}.Source(name, input)
+}
+
+// Source formats slang source. Input that cannot be parsed is returned with the ParseError.
+func (cfg Config) Source(name, input string) (string, error) {
+	root, err := parser.ParseCST(name, input)
+	if err != nil {
+		return input, err
+	}
+	return cfg.Node(root), nil
+}
+
+// Node formats a concrete syntax tree. File nodes are formatted as a sequence of top-level forms
+// ending with a newline.
+func (cfg Config) Node(n *parser.Node) string {
+	f := &formatter{width: cfg.Width, flat: map[*parser.Node]string{}}
+	if f.width <= 0 {
+		f.width = DefaultWidth
+	}
+	if n.Kind != parser.NodeFile {
+		f.node(n)
+		return f.b.String()
+	}
+
+	for i, e := range elements(n.Children) {
+		if i > 0 {
+			if e.isTrailingComment() {
+				f.write(" ")
+			} else {
+				f.newline(0, e.newlines > 1)
+			}
+		}
+		f.node(e.node)
+	}
+	if f.b.Len() > 0 {
+		f.b.WriteByte('\n')
+	}
+	return f.b.String()
+}
+
+// element is a form or comment of a sequence with the number of line endings before it.
+type element struct {
+	node     *parser.Node
+	newlines int
+	first    bool // no form or comment comes before the element
+}
+
+func (e element) isComment() bool {
+	return e.node.Kind == parser.NodeComment
+}
+
+// isTrailingComment returns true if the element is a comment on the same line as the element
+// before it.
+func (e element) isTrailingComment() bool {
+	return e.isComment() && !e.first && e.newlines == 0
+}
+
+// elements returns the forms and comments of children. Whitespace is dropped.
+func elements(children []*parser.Node) []element {
+	var elems []element
+	newlines := 0
+	for _, child := range children {
+		if child.Kind == parser.NodeWhitespace {
+			newlines += strings.Count(child.Text, "\n")
+			continue
+		}
+		elems = append(elems, element{node: child, newlines: newlines, first: len(elems) == 0})
+		newlines = 0
+	}
+	return elems
+}
+
+type formatter struct {
+	b     strings.Builder
+	width int
+	col   int                     // column of the next rune written, starting at 0
+	flat  map[*parser.Node]string // cached one-line renderings
+}
+
+func (f *formatter) write(s string) {
+	f.b.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		f.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		f.col += utf8.RuneCountInString(s)
+	}
+}
+
+// newline ends the current line and indents the next one. If blank is true, an empty line is
+// written in between.
+func (f *formatter) newline(indent int, blank bool) {
+	f.b.WriteByte('\n')
+	if blank {
+		f.b.WriteByte('\n')
+	}
+	f.b.WriteString(strings.Repeat(" ", indent))
+	f.col = indent
+}
+
+// oneLine returns the rendering of n on a single line. It returns false if n contains a comment or
+// a string spanning several lines.
+func (f *formatter) oneLine(n *parser.Node) (string, bool) {
+	if s, cached := f.flat[n]; cached {
+		return s, s != ""
+	}
+
+	var b strings.Builder
+	ok := true
+	switch n.Kind {
+	case parser.NodeAtom:
+		b.WriteString(n.Text)
+		ok = !strings.Contains(n.Text, "\n")
+	case parser.NodeComment:
+		ok = false
+	case parser.NodeQuote, parser.NodeTagged:
+		b.WriteString(prefix(n))
+		for _, e := range elements(n.Children) {
+			s, fits := f.oneLine(e.node)
+			b.WriteString(s)
+			ok = ok && fits
+		}
+	default:
+		b.WriteString(n.Text)
+		for i, e := range elements(n.Children) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			s, fits := f.oneLine(e.node)
+			b.WriteString(s)
+			ok = ok && fits
+		}
+		b.WriteString(n.Close)
+	}
+
+	if !ok {
+		f.flat[n] = ""
+		return "", false
+	}
+	f.flat[n] = b.String()
+	return b.String(), true
+}
+
+// fits returns true if n can be written on one line without passing the line width.
+func (f *formatter) fits(n *parser.Node, col int) bool {
+	s, ok := f.oneLine(n)
+	return ok && col+utf8.RuneCountInString(s) <= f.width
+}
+
+// prefix returns the text written before the form of a quote or tagged literal.
+func prefix(n *parser.Node) string {
+	if n.Kind == parser.NodeTagged {
+		return n.Text + " "
+	}
+	return n.Text
+}
+
+func (f *formatter) node(n *parser.Node) {
+	switch n.Kind {
+	case parser.NodeAtom:
+		f.write(n.Text)
+	case parser.NodeComment:
+		f.write(strings.TrimRight(n.Text, " \t\r"))
+	case parser.NodeQuote, parser.NodeTagged:
+		start := f.col
+		f.write(prefix(n))
+		afterComment := false
+		for _, e := range elements(n.Children) {
+			if afterComment {
+				f.newline(start, false)
+			}
+			f.node(e.node)
+			afterComment = e.isComment()
+		}
+	default:
+		if !alwaysBreaks(n) && f.fits(n, f.col) {
+			s, _ := f.oneLine(n)
+			f.write(s)
+			return
+		}
+		f.sequence(n)
+	}
+}
+
+// alwaysBreaks returns true if n is a procedure definition, whose body always starts on a new line.
+func alwaysBreaks(n *parser.Node) bool {
+	elems := elements(n.Children)
+	return n.Kind == parser.NodeList && len(elems) > 3 && operator(elems) == "define" &&
+		elems[2].node.Kind == parser.NodeVector
+}
+
+// operator returns the symbol at the head of a list, or "" if the list does not start with a symbol.
+func operator(elems []element) string {
+	if len(elems) == 0 {
+		return ""
+	}
+	sym, _ := elems[0].node.Form().(slang.Symbol)
+	return string(sym)
+}
+
+// sequence writes a list, vector or map that does not fit on one line.
+func (f *formatter) sequence(n *parser.Node) {
+	start := f.col
+	f.write(n.Text)
+	elems := elements(n.Children)
+
+	// inline is the number of leading forms kept on the line of the opening delimiter; later forms
+	// start new lines at indent. Sequences without rules fill lines up to the width.
+	inline, indent, fill := 0, start+1, true
+	switch n.Kind {
+	case parser.NodeList:
+		if op := operator(elems); op != "" {
+			fill = false
+			if args, isBlock := blockForms[op]; isBlock {
+				inline, indent = 1+args, start+2
+				if alwaysBreaks(n) {
+					inline++ // parameters of a procedure definition
+				}
+			} else {
+				inline, indent = 2, start+utf8.RuneCountInString(n.Text+op)+1
+			}
+		}
+	case parser.NodeMap:
+		fill = false
+	}
+
+	forms := 0
+	afterComment := false
+	for i, e := range elems {
+		switch {
+		case i == 0:
+		case e.isTrailingComment():
+			f.write(" ")
+		case afterComment:
+			f.newline(indent, e.newlines > 1)
+		case n.Kind == parser.NodeMap && forms%2 == 1:
+			f.write(" ") // value on the line of its key
+		case forms < inline:
+			f.write(" ")
+		case fill && !e.isComment() && f.fits(e.node, f.col+1):
+			f.write(" ")
+		default:
+			f.newline(indent, e.newlines > 1)
+		}
+		f.node(e.node)
+		afterComment = e.isComment()
+		if !afterComment {
+			forms++
+		}
+	}
+	if afterComment {
+		f.newline(indent, false)
+	}
+	f.write(n.Close)
+}
@@ -0,0 +1,100 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang/parser"
+	"github.com/zachorosz/slang/printer"
+)
+
+var formatTests = []struct {
+	name  string
+	input string
+	want  string
+}{
+	{"empty", "", ""},
+	{"spacing", "  ( +   1\n 2 )  ", "(+ 1 2)\n"},
+	{"blank lines", "(a)\n\n\n\n(b)\n(c)", "(a)\n\n(b)\n(c)\n"},
+	{"procedure definition", "(define square [x] (* x x))", "(define square [x]\n  (* x x))\n"},
+	{"value definition", "(define x\n  1)", "(define x 1)\n"},
+	{"docstring", "(define f [] \"Returns 1.\" 1)", "(define f []\n  \"Returns 1.\"\n  1)\n"},
+	{"comments", "; header\n(define x 1)   ; trailing\n", "; header\n(define x 1) ; trailing\n"},
+	{"comment in body", "(begin\n; first\n(f))", "(begin\n  ; first\n  (f))\n"},
+	{"comment before close", "[1 2 ; two\n]", "[1 2 ; two\n ]\n"},
+	{"quote and tag", "' ( a b )\n#inst   \"2020-01-02\"", "'(a b)\n#inst \"2020-01-02\"\n"},
+	{"multi-line string", "(f \"a\nb\" c)", "(f \"a\nb\"\n   c)\n"},
+	{
+		"if",
+		"(if (some-long-predicate-name first-argument) (consequent-procedure a b c) (alternative x))",
+		"(if (some-long-predicate-name first-argument)\n  (consequent-procedure a b c)\n  (alternative x))\n",
+	},
+	{
+		"lambda",
+		"(lambda [first-parameter second-parameter] (+ first-parameter second-parameter 1 2 3))",
+		"(lambda [first-parameter second-parameter]\n  (+ first-parameter second-parameter 1 2 3))\n",
+	},
+	{
+		"call",
+		"(string-join (list \"first string\" \"second string\") (repeat-string \"-\" 20 \"separator\"))",
+		"(string-join (list \"first string\" \"second string\")\n             (repeat-string \"-\" 20 \"separator\"))\n",
+	},
+	{
+		"fill vector",
+		"[1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20 21 22 23 24 25 26 27 28 29 30 31]",
+		"[1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20 21 22 23 24 25 26 27 28 29\n 30 31]\n",
+	},
+	{
+		"map pairs",
+		"{\"name\" \"a fairly long name value\" \"description\" \"an even longer description value\"}",
+		"{\"name\" \"a fairly long name value\"\n \"description\" \"an even longer description value\"}\n",
+	},
+}
+
+func TestSource(t *testing.T) {
+	for _, test := range formatTests {
+		got, err := Source("test", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %s", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.name, got, test.want)
+		}
+		if again, _ := Source("test", got); again != got {
+			t.Errorf("\n%s:\n\tgot %q when formatted twice\n\texp %q", test.name, again, got)
+		}
+	}
+}
+
+func TestSourceKeepsForms(t *testing.T) {
+	input := "(define fib [n] \"Returns the nth Fibonacci number.\" (if (< n 2) n ; base case\n" +
+		"(+ (fib (- n 1)) (fib (- n 2)))))\n{a [1 2] \"b\" #uuid \"5b1c3c3e-8e25-4fbb-9f31-2b6f2a8f3c10\"}\n"
+	for _, width := range []int{10, 40, 80} {
+		got, err := Config{Width: width}.Source("test", input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := parser.Parse("test", input)
+		forms, err := parser.Parse("test", got)
+		if err != nil {
+			t.Fatalf("formatted source does not parse: %s\n%s", err, got)
+		}
+		for i := range want {
+			if printer.PrStr(forms[i]) != printer.PrStr(want[i]) {
+				t.Errorf("\nwidth %d:\n\tgot %s\n\texp %s", width, printer.PrStr(forms[i]), printer.PrStr(want[i]))
+			}
+		}
+		if !strings.Contains(got, "; base case") {
+			t.Errorf("\nwidth %d:\n\tgot %q\n\texp comment to be kept", width, got)
+		}
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	input := "(define x"
+	got, err := Source("test", input)
+	if !parser.IsIncomplete(err) || got != input {
+		t.Errorf("\n%s:\n\tgot %q, %v\n\texp input and incomplete parse error", "TestSourceError", got, err)
+	}
+}
@@ -0,0 +1,474 @@
+// Package gen generates random slang values for property-based tests. Each generator is a Gen, which
+// implements slang.Generator for the for-all form and shrinks failing values to simpler ones. Value
+// wraps a random value of any type for checks with testing/quick:
+//
+//	quick.Check(func(v gen.Value) bool { return slang.Eq(v.LangType, v.LangType) }, nil)
+package gen
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/zachorosz/slang"
+)
+
+// Gen generates random values of a type and shrinks them. Use the constructors of this package to
+// make a Gen.
+type Gen struct {
+	generate func(r *rand.Rand, size int) slang.LangType
+	shrink   func(value slang.LangType) []slang.LangType
+}
+
+// Generate returns a random value. size bounds the magnitude of numbers and the length of strings
+// and collections.
+func (g Gen) Generate(r *rand.Rand, size int) slang.LangType {
+	return g.generate(r, size)
+}
+
+// Shrink returns values simpler than value, simplest first. It returns nothing if value cannot be
+// shrunk or was not made by the generator.
+func (g Gen) Shrink(value slang.LangType) []slang.LangType {
+	return g.shrink(value)
+}
+
+func (g Gen) String() string {
+	return "<generator>"
+}
+
+// Value is a random slang value of any type. It implements the Generator interface of testing/quick.
+type Value struct {
+	slang.LangType
+}
+
+// Generate returns a random Value with Any.
+func (Value) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Value{Any().Generate(r, size)})
+}
+
+// Number generates integers with magnitudes up to size and, a quarter of the time, fractions around
+// 0 with a standard deviation of size.
+func Number() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			if r.Intn(4) == 0 {
+				return slang.Number(r.NormFloat64() * float64(size))
+			}
+			return slang.Number(r.Intn(2*size+1) - size)
+		},
+		shrink: shrinkNumber,
+	}
+}
+
+// Integer generates integers with magnitudes up to size.
+func Integer() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return slang.Number(r.Intn(2*size+1) - size)
+		},
+		shrink: shrinkNumber,
+	}
+}
+
+// shrinkNumber shrinks a number towards 0: to 0, its integer part, its absolute value, half of it
+// and one closer to 0.
+func shrinkNumber(value slang.LangType) []slang.LangType {
+	n, isNumber := value.(slang.Number)
+	if !isNumber || n == 0 {
+		return nil
+	}
+	whole := slang.Number(int64(n))
+	candidates := []slang.Number{0, whole, -n, slang.Number(int64(n / 2))}
+	if n > 0 {
+		candidates = append(candidates, whole-1)
+	} else {
+		candidates = append(candidates, whole+1)
+	}
+
+	var shrunk []slang.LangType
+	seen := map[slang.Number]bool{n: true}
+	for _, c := range candidates {
+		if (c == -n && n > 0) || seen[c] || abs(c) > abs(n) {
+			continue
+		}
+		seen[c] = true
+		shrunk = append(shrunk, c)
+	}
+	return shrunk
+}
+
+func abs(n slang.Number) slang.Number {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Bool generates true and false.
+func Bool() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return r.Intn(2) == 0
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			if value == true {
+				return []slang.LangType{false}
+			}
+			return nil
+		},
+	}
+}
+
+// runes are the runes of generated characters and strings: mostly ASCII letters and digits, with
+// punctuation, whitespace and runes that need escaping or several bytes.
+var runes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789" +
+	" !#$%&'()*+,-./:;<=>?@[]^_`{|}~" + "\"\\\n\t\r\x00\x7f" + "éλ€日☃😀")
+
+func randomRune(r *rand.Rand) rune {
+	return runes[r.Intn(len(runes))]
+}
+
+// Char generates characters.
+func Char() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return slang.Char(randomRune(r))
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			if c, isChar := value.(slang.Char); isChar && c != 'a' {
+				return []slang.LangType{slang.Char('a')}
+			}
+			return nil
+		},
+	}
+}
+
+// Str generates strings of up to size runes.
+func Str() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			s := make([]rune, r.Intn(size+1))
+			for i := range s {
+				s[i] = randomRune(r)
+			}
+			return slang.Str(s)
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			s, isStr := value.(slang.Str)
+			if !isStr {
+				return nil
+			}
+			var shrunk []slang.LangType
+			for _, shorter := range shrinkRunes([]rune(string(s))) {
+				shrunk = append(shrunk, slang.Str(shorter))
+			}
+			return shrunk
+		},
+	}
+}
+
+// shrinkRunes returns shorter sequences of runes: no runes, each half and s without each rune, then
+// s with each rune replaced by 'a'.
+func shrinkRunes(s []rune) [][]rune {
+	if len(s) == 0 {
+		return nil
+	}
+	shrunk := [][]rune{{}}
+	if len(s) > 1 {
+		shrunk = append(shrunk, s[:len(s)/2], s[len(s)/2:])
+	}
+	for i := range s {
+		if len(s) > 2 {
+			shrunk = append(shrunk, append(append([]rune{}, s[:i]...), s[i+1:]...))
+		}
+	}
+	for i, c := range s {
+		if c != 'a' {
+			simpler := append([]rune{}, s...)
+			simpler[i] = 'a'
+			shrunk = append(shrunk, simpler)
+		}
+	}
+	return shrunk
+}
+
+// symbolRunes are the runes that may follow the first letter of a symbol.
+const symbolRunes = "abcdefghijklmnopqrstuvwxyz0123456789!$%&*_+-=<>?/"
+
+// Symbol generates symbols of up to size+1 runes. Symbols start with a letter and are never true,
+// false or nil, which the parser reads as other values.
+func Symbol() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			for {
+				var b strings.Builder
+				b.WriteByte(byte('a' + r.Intn(26)))
+				for i := r.Intn(size + 1); i > 0; i-- {
+					b.WriteByte(symbolRunes[r.Intn(len(symbolRunes))])
+				}
+				if validSymbol(b.String()) {
+					return slang.Symbol(b.String())
+				}
+			}
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			symbol, isSymbol := value.(slang.Symbol)
+			if !isSymbol || symbol == "a" {
+				return nil
+			}
+			shrunk := []slang.LangType{slang.Symbol("a")}
+			for i := 1; i < len(symbol); i++ {
+				if validSymbol(string(symbol[:i])) {
+					shrunk = append(shrunk, symbol[:i])
+				}
+			}
+			return shrunk
+		},
+	}
+}
+
+func validSymbol(s string) bool {
+	return s != "true" && s != "false" && s != "nil"
+}
+
+// Inst generates instants between 1970 and 2514 with nanoseconds and time zone offsets. Instants
+// shrink to the Unix epoch in UTC.
+func Inst() Gen {
+	epoch := time.Unix(0, 0).UTC()
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			zone := time.FixedZone("", (r.Intn(48)-24)*30*60)
+			return slang.Inst{Time: time.Unix(r.Int63n(1<<34), r.Int63n(1e9)).In(zone)}
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			if inst, isInst := value.(slang.Inst); isInst && !inst.Time.Equal(epoch) {
+				return []slang.LangType{slang.Inst{Time: epoch}}
+			}
+			return nil
+		},
+	}
+}
+
+// UUID generates random UUIDs. UUIDs shrink to the nil UUID.
+func UUID() Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			var uuid slang.UUID
+			r.Read(uuid[:])
+			return uuid
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			if uuid, isUUID := value.(slang.UUID); isUUID && uuid != (slang.UUID{}) {
+				return []slang.LangType{slang.UUID{}}
+			}
+			return nil
+		},
+	}
+}
+
+// Elements generates one of values, and shrinks to the values before it.
+func Elements(values ...slang.LangType) Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return values[r.Intn(len(values))]
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			for i, v := range values {
+				if slang.Eq(v, value) {
+					return values[:i]
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// OneOf generates a value with one of gens. Values are shrunk by each of gens.
+func OneOf(gens ...Gen) Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return gens[r.Intn(len(gens))].Generate(r, size)
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			var shrunk []slang.LangType
+			for _, g := range gens {
+				shrunk = append(shrunk, g.Shrink(value)...)
+			}
+			return shrunk
+		},
+	}
+}
+
+// items generates up to size items with g. The size is shared by the items, so nested collections
+// stay small.
+func items(g Gen, r *rand.Rand, size int) []slang.LangType {
+	n := r.Intn(size + 1)
+	values := make([]slang.LangType, n)
+	for i := range values {
+		values[i] = g.Generate(r, size/n)
+	}
+	return values
+}
+
+// shrinkItems returns shorter sequences of items, and the sequence with each item shrunk by g.
+func shrinkItems(g Gen, values []slang.LangType) [][]slang.LangType {
+	if len(values) == 0 {
+		return nil
+	}
+	shrunk := [][]slang.LangType{{}}
+	if len(values) > 1 {
+		shrunk = append(shrunk, values[:len(values)/2], values[len(values)/2:])
+	}
+	for i := range values {
+		if len(values) > 2 {
+			shrunk = append(shrunk, append(append([]slang.LangType{}, values[:i]...), values[i+1:]...))
+		}
+	}
+	for i, value := range values {
+		for _, item := range g.Shrink(value) {
+			replaced := append([]slang.LangType{}, values...)
+			replaced[i] = item
+			shrunk = append(shrunk, replaced)
+		}
+	}
+	return shrunk
+}
+
+// ListOf generates lists of up to size items generated by g.
+func ListOf(g Gen) Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return makeList(items(g, r, size))
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			lst, isList := value.(slang.List)
+			if !isList {
+				return nil
+			}
+			var shrunk []slang.LangType
+			for _, values := range shrinkItems(g, lst.Items()) {
+				shrunk = append(shrunk, makeList(values))
+			}
+			return shrunk
+		},
+	}
+}
+
+func makeList(values []slang.LangType) slang.List {
+	lst := slang.List{}
+	for _, value := range values {
+		lst = lst.Append(value).(slang.List)
+	}
+	return lst
+}
+
+// VectorOf generates vectors of up to size items generated by g.
+func VectorOf(g Gen) Gen {
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			return slang.Vector(items(g, r, size))
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			vec, isVec := value.(slang.Vector)
+			if !isVec {
+				return nil
+			}
+			var shrunk []slang.LangType
+			for _, values := range shrinkItems(g, vec) {
+				shrunk = append(shrunk, slang.Vector(values))
+			}
+			return shrunk
+		},
+	}
+}
+
+// MapOf generates maps of up to size keys generated by keys, mapped to values generated by values.
+// keys must generate values that can be map keys.
+func MapOf(keys, values Gen) Gen {
+	// a map is shrunk as a sequence of its alternating keys and values
+	entries := Gen{shrink: func(value slang.LangType) []slang.LangType {
+		return append(keys.Shrink(value), values.Shrink(value)...)
+	}}
+	return Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			n := r.Intn(size + 1)
+			kvs := make([]slang.LangType, 0, 2*n)
+			for i := 0; i < n; i++ {
+				kvs = append(kvs, keys.Generate(r, size/n), values.Generate(r, size/n))
+			}
+			m, _ := slang.MakeMap(kvs...)
+			return m
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			m, isMap := value.(slang.Map)
+			if !isMap || m.Len() == 0 {
+				return nil
+			}
+			var kvs []slang.LangType
+			for _, key := range m.Keys() {
+				v, _ := m.Get(key)
+				kvs = append(kvs, key, v)
+			}
+			var shrunk []slang.LangType
+			for _, entry := range shrinkItems(entries, kvs) {
+				// shorter sequences may split a key from its value
+				if len(entry)%2 != 0 {
+					continue
+				}
+				if shorter, err := slang.MakeMap(entry...); err == nil && shorter.Len() <= m.Len() {
+					shrunk = append(shrunk, shorter)
+				}
+			}
+			return shrunk
+		},
+	}
+}
+
+// Key generates values that can be map keys: numbers, strings, symbols, characters, booleans and
+// UUIDs.
+func Key() Gen {
+	return OneOf(Number(), Str(), Symbol(), Char(), Bool(), UUID())
+}
+
+// Scalar generates values that are not collections: nil, instants and the values of Key.
+func Scalar() Gen {
+	return OneOf(Number(), Str(), Symbol(), Char(), Bool(), UUID(), Inst(), Elements(nil))
+}
+
+// Any generates values of every type, including collections nested up to size levels deep.
+// Collections also shrink to the values they contain.
+func Any() Gen {
+	scalar := Scalar()
+	var g Gen
+	g = Gen{
+		generate: func(r *rand.Rand, size int) slang.LangType {
+			if size == 0 || r.Intn(3) > 0 {
+				return scalar.Generate(r, size)
+			}
+			switch r.Intn(3) {
+			case 0:
+				return ListOf(g).Generate(r, size)
+			case 1:
+				return VectorOf(g).Generate(r, size)
+			default:
+				return MapOf(Key(), g).Generate(r, size)
+			}
+		},
+		shrink: func(value slang.LangType) []slang.LangType {
+			var contents, shrunk []slang.LangType
+			switch t := value.(type) {
+			case slang.List:
+				contents, shrunk = t.Items(), ListOf(g).Shrink(t)
+			case slang.Vector:
+				contents, shrunk = t, VectorOf(g).Shrink(t)
+			case slang.Map:
+				contents, shrunk = t.Values(), MapOf(Key(), g).Shrink(t)
+			default:
+				return scalar.Shrink(value)
+			}
+			return append(contents, shrunk...)
+		},
+	}
+	return g
+}
@@ -0,0 +1,81 @@
+package gen_test
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/gen"
+	"github.com/zachorosz/slang/parser"
+	"github.com/zachorosz/slang/printer"
+)
+
+var shrinkTests = []struct {
+	name string
+	gen  gen.Gen
+	x    slang.LangType
+	want string
+}{
+	{"number", gen.Number(), slang.Number(-7.5), "[0 -7 7.5 -3 -6]"},
+	{"zero", gen.Integer(), slang.Number(0), "nil"},
+	{"bool", gen.Bool(), true, "[false]"},
+	{"string", gen.Str(), slang.Str("xyz"), `["" "x" "yz" "yz" "xz" "xy" "ayz" "xaz" "xya"]`},
+	{"symbol", gen.Symbol(), slang.Symbol("nile"), "[a n ni]"},
+	{"elements", gen.Elements(1, 2, 3), 3, "[1 2]"},
+	{"list", gen.ListOf(gen.Integer()), slang.MakeList(slang.Number(2), slang.Number(0)), "[() (2) (0) (0 0) (1 0)]"},
+	{"vector", gen.VectorOf(gen.Bool()), slang.Vector{true}, "[[] [false]]"},
+	{"map", gen.MapOf(gen.Str(), gen.Bool()), mustMap(slang.Str("b"), true), `[{} {"" true} {"a" true} {"b" false}]`},
+	{"any", gen.Any(), slang.Vector{slang.Number(1)}, "[1 [] [0]]"},
+	{"wrong type", gen.Str(), slang.Number(1), "nil"},
+}
+
+func mustMap(kvs ...slang.LangType) slang.Map {
+	m, err := slang.MakeMap(kvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestShrink(t *testing.T) {
+	for _, test := range shrinkTests {
+		shrunk := test.gen.Shrink(test.x)
+		got := "nil"
+		if shrunk != nil {
+			got = printer.PrStr(slang.Vector(shrunk))
+		}
+		if got != test.want {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSymbolsRead(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		symbol := gen.Symbol().Generate(r, i%10)
+		forms, err := parser.Parse("TestSymbolsRead", printer.PrStr(symbol))
+		if err != nil || len(forms) != 1 || forms[0] != symbol {
+			t.Fatalf("\n%s:\n\tgot %v %v\n\texp %s", "TestSymbolsRead", forms, err, symbol)
+		}
+	}
+}
+
+func TestGenerateSize(t *testing.T) {
+	// size 0 generates scalars whose numbers are 0 and strings are empty
+	zero := func(seed int64) bool {
+		switch x := gen.Any().Generate(rand.New(rand.NewSource(seed)), 0).(type) {
+		case slang.Number:
+			return x == 0
+		case slang.Str:
+			return x == ""
+		case slang.List, slang.Vector, slang.Map:
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(zero, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,94 @@
+package slang
+
+import "fmt"
+
+// Source is the location of a form in source code. Lines and columns start at 1.
+type Source struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (src Source) String() string {
+	return fmt.Sprintf("%s:%d:%d", src.File, src.Line, src.Column)
+}
+
+// Hook observes evaluation, for tools like debuggers. Set a Hook with Env.SetHook. Returning an
+// error from a Hook method stops evaluation with the error.
+type Hook interface {
+	// Eval is called before expr is evaluated in env. stack is the call stack of the evaluation,
+	// outermost first.
+	Eval(expr LangType, env Env, stack []Frame) error
+	// Apply is called when a procedure is applied. The application is the last Frame of stack.
+	Apply(stack []Frame) error
+}
+
+// Frame is a procedure application on the call stack of an evaluation with a Hook. Tail calls
+// replace the Frame of their caller.
+type Frame struct {
+	Procedure LangType // Lambda or Subroutine
+	Args      []LangType
+	Form      List // form of the application
+	// Env is the environment of the body of a Lambda with its parameters bound, or the environment
+	// of the application for Subroutines.
+	Env Env
+}
+
+// Name returns the name of the procedure of the frame, or lambda if it is anonymous.
+func (frame Frame) Name() Symbol {
+	switch t := frame.Procedure.(type) {
+	case Lambda:
+		if t.name != "" {
+			return t.name
+		}
+	case Subroutine:
+		return t.Name
+	}
+	return Symbol("lambda")
+}
+
+// hookState is shared by an environment and every environment enclosed by it.
+type hookState struct {
+	hook       Hook
+	stack      []Frame
+	traceDepth int // number of traced applications in progress
+}
+
+// Hook returns the Hook called by Evaluate in env, or nil.
+func (env *Env) Hook() Hook {
+	if env.hooks == nil {
+		return nil
+	}
+	return env.hooks.hook
+}
+
+// SetHook sets the Hook called by Evaluate in env, the environments enclosing it and all
+// environments enclosed by them. A nil hook removes the Hook.
+func (env *Env) SetHook(hook Hook) {
+	if env.hooks == nil {
+		env.hooks = &hookState{}
+	}
+	env.hooks.hook = hook
+}
+
+// evaluateHooked evaluates expr with the hook of env and removes the frames the evaluation pushed
+// on the call stack.
+func evaluateHooked(expr LangType, env Env) (LangType, error) {
+	depth := len(env.hooks.stack)
+	result, err := evaluate(expr, env, env.hooks)
+	if len(env.hooks.stack) > depth {
+		env.hooks.stack = env.hooks.stack[:depth]
+	}
+	return result, err
+}
+
+// enter pushes the frame of a procedure application on the call stack and calls the Apply hook. If
+// tail is true, the frame replaces the last frame.
+func (hooks *hookState) enter(frame Frame, tail bool) error {
+	if tail {
+		hooks.stack[len(hooks.stack)-1] = frame
+	} else {
+		hooks.stack = append(hooks.stack, frame)
+	}
+	return hooks.hook.Apply(hooks.stack)
+}
@@ -0,0 +1,95 @@
+package slang
+
+import (
+	"errors"
+	"testing"
+)
+
+// recordingHook records the names on the call stack at each application.
+type recordingHook struct {
+	evals   int
+	applies [][]Symbol
+	stopAt  Symbol
+}
+
+func (hook *recordingHook) Eval(expr LangType, env Env, stack []Frame) error {
+	hook.evals++
+	return nil
+}
+
+func (hook *recordingHook) Apply(stack []Frame) error {
+	var names []Symbol
+	for _, frame := range stack {
+		names = append(names, frame.Name())
+	}
+	hook.applies = append(hook.applies, names)
+	if stack[len(stack)-1].Name() == hook.stopAt {
+		return errors.New("Stopped")
+	}
+	return nil
+}
+
+func TestHookStack(t *testing.T) {
+	env := MakeEnv(nil)
+	env.Define(Symbol("inc"), Subroutine{Name: "inc", Func: func(args ...LangType) (LangType, error) {
+		return args[0].(Number) + 1, nil
+	}})
+	// (define f (lambda [x] (g (inc x))))
+	// (define g (lambda [x] x))
+	Evaluate(MakeList(Symbol("define"), Symbol("g"),
+		MakeList(Symbol("lambda"), MakeVector(Symbol("x")), Symbol("x"))), env)
+	Evaluate(MakeList(Symbol("define"), Symbol("f"),
+		MakeList(Symbol("lambda"), MakeVector(Symbol("x")),
+			MakeList(Symbol("g"), MakeList(Symbol("inc"), Symbol("x"))))), env)
+
+	hook := &recordingHook{}
+	env.SetHook(hook)
+	result, err := Evaluate(MakeList(Symbol("f"), Number(1)), env)
+	if err != nil || result != Number(2) {
+		t.Fatalf("\n%s:\n\tgot %v %v\n\texp 2", "TestHookStack", result, err)
+	}
+	if hook.evals == 0 {
+		t.Errorf("\n%s:\n\tgot no Eval calls", "TestHookStack")
+	}
+
+	// the tail call to g replaces the frame of f
+	want := [][]Symbol{{"f"}, {"f", "inc"}, {"g"}}
+	if len(hook.applies) != len(want) {
+		t.Fatalf("\n%s:\n\tgot %v\n\texp %v", "TestHookStack", hook.applies, want)
+	}
+	for i := range want {
+		if !Eq(symbolVector(hook.applies[i]), symbolVector(want[i])) {
+			t.Errorf("\n%s:\n\tgot %v\n\texp %v", "TestHookStack", hook.applies, want)
+		}
+	}
+
+	// the stack is empty again after an error from the hook
+	hook.stopAt, hook.applies = "inc", nil
+	if _, err := Evaluate(MakeList(Symbol("f"), Number(1)), env); err == nil || err.Error() != "Stopped" {
+		t.Errorf("\n%s:\n\tgot %v\n\texp Stopped", "TestHookStack", err)
+	}
+	hook.stopAt, hook.applies = "", nil
+	Evaluate(MakeList(Symbol("g"), Number(1)), env)
+	if len(hook.applies) != 1 || len(hook.applies[0]) != 1 {
+		t.Errorf("\n%s:\n\tgot %v\n\texp [[g]]", "TestHookStack", hook.applies)
+	}
+
+	env.SetHook(nil)
+	hook.evals = 0
+	Evaluate(MakeList(Symbol("f"), Number(1)), env)
+	if hook.evals != 0 {
+		t.Errorf("\n%s:\n\tgot %d Eval calls after removing the hook", "TestHookStack", hook.evals)
+	}
+}
+
+func TestHookZeroEnv(t *testing.T) {
+	var env Env
+	if hook := env.Hook(); hook != nil {
+		t.Errorf("\n%s:\n\tgot %v\n\texp nil", "TestHookZeroEnv", hook)
+	}
+	hook := &recordingHook{}
+	env.SetHook(hook)
+	if got := env.Hook(); got != hook {
+		t.Errorf("\n%s:\n\tgot %v\n\texp %v", "TestHookZeroEnv", got, hook)
+	}
+}
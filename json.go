@@ -0,0 +1,218 @@
+package slang
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// FromJSON decodes a JSON document into slang values. Objects become Maps with Str keys in document
+// order, arrays become Vectors, numbers become Numbers and null becomes nil.
+// Usage: `(json/parse s)`
+func FromJSON(data []byte) (LangType, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	value, err := decodeJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JSON: %s", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("Invalid JSON: unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+func decodeJSON(dec *json.Decoder) (LangType, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			vec := Vector{}
+			for dec.More() {
+				item, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				vec = append(vec, item)
+			}
+			_, err := dec.Token() // closing ']'
+			return vec, err
+		case '{':
+			m := Map{index: map[LangType]int{}}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				// keys are unique strings; update in place rather than copying with Assoc
+				k := Str(key.(string))
+				if i, exists := m.index[k]; exists {
+					m.values[i] = value
+					continue
+				}
+				m.index[k] = len(m.keys)
+				m.keys = append(m.keys, k)
+				m.values = append(m.values, value)
+			}
+			_, err := dec.Token() // closing '}'
+			return m, err
+		}
+		return nil, fmt.Errorf("unexpected %s", t)
+	case string:
+		return Str(t), nil
+	case float64:
+		return Number(t), nil
+	case bool:
+		return t, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %v", t)
+	}
+}
+
+// ToJSON encodes a slang value as JSON. Maps must have Str, Symbol, Char, UUID or Number keys, and
+// no two keys of a Map may have the same name, like "1" and 1. Lists and Vectors become arrays; Insts
+// and UUIDs become strings. Values without a JSON representation, such as Lambdas, Subroutines and
+// Vectors that contain themselves, cannot be encoded. If pretty is true, the output is indented by
+// two spaces per level.
+// Usage: `(json/stringify x pretty?)`
+func ToJSON(x LangType, pretty bool) ([]byte, error) {
+	var b bytes.Buffer
+	if err := encodeJSON(&b, x, map[jsonContainer]bool{}); err != nil {
+		return nil, err
+	}
+	if !pretty {
+		return b.Bytes(), nil
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, b.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// jsonContainer identifies a Vector or the values of a Map by its backing array and length. Vectors
+// share backing arrays, so a value can contain itself if an item is assigned from Go.
+type jsonContainer struct {
+	first *LangType
+	len   int
+}
+
+// encodeJSON writes x to b. visiting holds the containers being encoded, for cycle detection.
+func encodeJSON(b *bytes.Buffer, x LangType, visiting map[jsonContainer]bool) error {
+	switch t := x.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		fmt.Fprint(b, t)
+	case Number:
+		if math.IsInf(float64(t), 0) || math.IsNaN(float64(t)) {
+			return fmt.Errorf("Cannot serialize %s to JSON", t)
+		}
+		encoded, _ := json.Marshal(float64(t))
+		b.Write(encoded)
+	case Str:
+		encodeJSONString(b, string(t))
+	case Char:
+		encodeJSONString(b, string(t))
+	case Symbol:
+		encodeJSONString(b, string(t))
+	case Inst:
+		encodeJSONString(b, t.Format(time.RFC3339Nano))
+	case UUID:
+		encodeJSONString(b, t.Canonical())
+	case List, Vector:
+		items := ToSlice(t.(Sequence))
+		if vec, isVec := t.(Vector); isVec && len(vec) > 0 {
+			container := jsonContainer{&vec[0], len(vec)}
+			if visiting[container] {
+				return fmt.Errorf("Cannot serialize a vector that contains itself to JSON")
+			}
+			visiting[container] = true
+			defer delete(visiting, container)
+		}
+		b.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encodeJSON(b, item, visiting); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case Map:
+		if len(t.values) > 0 {
+			container := jsonContainer{&t.values[0], len(t.values)}
+			if visiting[container] {
+				return fmt.Errorf("Cannot serialize a map that contains itself to JSON")
+			}
+			visiting[container] = true
+			defer delete(visiting, container)
+		}
+		names := make(map[string]LangType, len(t.keys))
+		b.WriteByte('{')
+		for i, key := range t.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			name, err := jsonKey(key)
+			if err != nil {
+				return err
+			}
+			if other, exists := names[name]; exists {
+				return fmt.Errorf("Cannot serialize map keys %s and %s to JSON: both are named %q",
+					repr(other), repr(key), name)
+			}
+			names[name] = key
+			encodeJSONString(b, name)
+			b.WriteByte(':')
+			if err := encodeJSON(b, t.values[i], visiting); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("Cannot serialize %s (%T) to JSON", repr(x), x)
+	}
+	return nil
+}
+
+// jsonKey converts a Map key to the name of a JSON object member.
+func jsonKey(key LangType) (string, error) {
+	switch t := key.(type) {
+	case Str:
+		return string(t), nil
+	case Symbol:
+		return string(t), nil
+	case Char:
+		return string(t), nil
+	case UUID:
+		return t.Canonical(), nil
+	case Number:
+		return t.String(), nil
+	default:
+		return "", fmt.Errorf("Cannot serialize map key %s to JSON", repr(key))
+	}
+}
+
+func encodeJSONString(b *bytes.Buffer, s string) {
+	var encoded strings.Builder
+	enc := json.NewEncoder(&encoded)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	b.WriteString(strings.TrimSuffix(encoded.String(), "\n"))
+}
@@ -0,0 +1,118 @@
+package slang
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFromJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want LangType
+	}{
+		{`null`, nil},
+		{`true`, true},
+		{`-1.5e2`, Number(-150)},
+		{`"aé\n"`, Str("aé\n")},
+		{`[]`, Vector{}},
+		{`[1, [null, "x"]]`, MakeVector(Number(1), MakeVector(nil, Str("x")))},
+		{`{"z": 1, "a": {"b": []}}`, mustMap(t, Str("z"), Number(1), Str("a"), mustMap(t, Str("b"), Vector{}))},
+	}
+
+	for _, c := range cases {
+		got, err := FromJSON([]byte(c.in))
+		if err != nil {
+			t.Errorf("FromJSON(%s) returned unexpected error %s", c.in, err)
+		} else if !Eq(got, c.want) {
+			t.Errorf("FromJSON(%s) == %v, want %v", c.in, got, c.want)
+		}
+	}
+
+	// Key order is preserved.
+	got, _ := FromJSON([]byte(`{"z": 1, "a": 2, "m": 3}`))
+	if s := got.(Map).String(); s != `{"z" 1 "a" 2 "m" 3}` {
+		t.Errorf("FromJSON did not preserve key order, got %s", s)
+	}
+
+	for _, in := range []string{``, `{`, `[1,]`, `{"a" 1}`, `1 2`} {
+		if _, err := FromJSON([]byte(in)); err == nil {
+			t.Errorf("FromJSON(%s) did not return an error", in)
+		}
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	cases := []struct {
+		in   LangType
+		want string
+	}{
+		{nil, `null`},
+		{false, `false`},
+		{Number(1e21), `1e+21`},
+		{Number(0.5), `0.5`},
+		{Str("<\"a\">"), `"<\"a\">"`},
+		{Char('x'), `"x"`},
+		{Symbol("sym"), `"sym"`},
+		{MakeList(Number(1), Vector{}), `[1,[]]`},
+		{mustMap(t, Str("b"), Vector{}, Symbol("a"), nil, Number(1), Str("one")), `{"b":[],"a":null,"1":"one"}`},
+	}
+
+	for _, c := range cases {
+		got, err := ToJSON(c.in, false)
+		if err != nil {
+			t.Errorf("ToJSON(%v) returned unexpected error %s", c.in, err)
+		} else if string(got) != c.want {
+			t.Errorf("ToJSON(%v) == %s, want %s", c.in, got, c.want)
+		}
+	}
+
+	pretty, err := ToJSON(mustMap(t, Str("a"), MakeVector(Number(1))), true)
+	if err != nil {
+		t.Fatalf("ToJSON returned unexpected error %s", err)
+	}
+	if want := "{\n  \"a\": [\n    1\n  ]\n}"; string(pretty) != want {
+		t.Errorf("ToJSON pretty == %s, want %s", pretty, want)
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	lambda, _ := MakeLambda(MakeEnv(nil), Vector{}, MakeList(Number(1)))
+	cases := []struct {
+		in   LangType
+		want string
+	}{
+		{lambda, "Cannot serialize"},
+		{MakeVector(Subroutine{Name: "f"}), "Cannot serialize"},
+		{mustRegex(t, "a"), "Cannot serialize"},
+		{Number(math.NaN()), "Cannot serialize"},
+		{mustMap(t, nil, Number(1)), "Cannot serialize map key nil"},
+		{mustMap(t, Str("1"), Number(1), Number(1), Number(2)), `Cannot serialize map keys "1" and 1 to JSON: both are named "1"`},
+	}
+
+	for _, c := range cases {
+		_, err := ToJSON(c.in, false)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("ToJSON(%v) error == %v, want %q", c.in, err, c.want)
+		}
+	}
+}
+
+func TestToJSONCycles(t *testing.T) {
+	vec := Vector{Number(1), nil}
+	vec[1] = vec
+	if _, err := ToJSON(vec, false); err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Errorf("ToJSON of cyclic vector error == %v, want a vector that contains itself", err)
+	}
+	m := mustMap(t, Str("m"), nil)
+	m.values[0] = MakeVector(m)
+	if _, err := ToJSON(m, false); err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Errorf("ToJSON of cyclic map error == %v, want a map that contains itself", err)
+	}
+
+	// the same vector encoded twice side by side is not a cycle
+	inner := MakeVector(Number(1))
+	if got, err := ToJSON(MakeVector(inner, inner), false); err != nil || string(got) != "[[1],[1]]" {
+		t.Errorf("ToJSON of repeated vector == %s, %v, want [[1],[1]]", got, err)
+	}
+}
@@ -0,0 +1,573 @@
+// Package lint finds likely mistakes in slang source without evaluating it. Forms are walked with
+// the lexical scopes the evaluator would create, so problems in branches that rarely run are found
+// as well: undefined symbols, wrong numbers of arguments, malformed special forms, definitions that
+// shadow builtins, unused parameters and recursive calls outside of tail position.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// Severity is how serious a Diagnostic is.
+type Severity int
+
+// enumerated severities
+const (
+	Warning Severity = iota // suspicious, but evaluates
+	Error                   // fails when evaluated
+)
+
+var severityNames = map[Severity]string{
+	Warning: "warning",
+	Error:   "error",
+}
+
+func (severity Severity) String() string {
+	return severityNames[severity]
+}
+
+// MarshalText encodes the severity by name, so machine-readable output shows "error" or "warning".
+func (severity Severity) MarshalText() ([]byte, error) {
+	return []byte(severity.String()), nil
+}
+
+// UnmarshalText decodes a severity encoded by MarshalText.
+func (severity *Severity) UnmarshalText(text []byte) error {
+	for s, name := range severityNames {
+		if name == string(text) {
+			*severity = s
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown severity '%s'", text)
+}
+
+// codes identifying the kind of a Diagnostic
+const (
+	SyntaxError      = "syntax-error"
+	UndefinedSymbol  = "undefined-symbol"
+	ArityMismatch    = "arity-mismatch"
+	MalformedForm    = "malformed-form"
+	NotApplicable    = "not-applicable"
+	ShadowedBuiltin  = "shadowed-builtin"
+	UnusedBinding    = "unused-binding"
+	NonTailRecursion = "non-tail-recursion"
+)
+
+// Diagnostic is a problem found in source. The problem spans from Start up to, but not including,
+// End.
+type Diagnostic struct {
+	File     string          `json:"file"`
+	Start    parser.Position `json:"start"`
+	End      parser.Position `json:"end"`
+	Severity Severity        `json:"severity"`
+	Code     string          `json:"code"`
+	Message  string          `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%s: %s: %s (%s)", d.File, d.Start, d.Severity, d.Message, d.Code)
+}
+
+// Source lints slang source. Symbols defined in globals, such as the primitive packages, are known
+// to the source; globals may be nil. If the source has syntax errors, they are the only diagnostics
+// returned. Diagnostics are sorted by position.
+func Source(name, input string, globals *slang.Env) []Diagnostic {
+	root, err := parser.ParseCST(name, input)
+	if err != nil {
+		// parse again with recovery to report every syntax error
+		_, err := parser.ParseRecover(name, input)
+		var diagnostics []Diagnostic
+		for _, parseErr := range err.(parser.ErrorList) {
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     parseErr.File,
+				Start:    parseErr.Start,
+				End:      parseErr.End,
+				Severity: Error,
+				Code:     SyntaxError,
+				Message:  parseErr.Message,
+			})
+		}
+		return diagnostics
+	}
+	return Node(name, root, globals)
+}
+
+// Node lints the top-level forms of a concrete syntax tree returned by parser.ParseCST.
+func Node(name string, root *parser.Node, globals *slang.Env) []Diagnostic {
+	l := &linter{file: name, globals: globals}
+	top := &scope{bindings: map[slang.Symbol]*binding{}}
+	program := forms(root)
+	l.collectDefines(program, top)
+	for _, form := range program {
+		l.form(form, top, nil, false)
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Start.Offset < l.diagnostics[j].Start.Offset
+	})
+	return l.diagnostics
+}
+
+// binding is a symbol bound by a definition or parameter.
+type binding struct {
+	node  *parser.Node
+	kind  string // "Parameter", "Definition" or "Binding"
+	arity int    // number of arguments of a procedure, or -1 if unknown
+	used  bool
+}
+
+// scope is a lexical scope. Scopes mirror the environments the evaluator creates for procedure
+// bodies and with-open.
+type scope struct {
+	outer    *scope
+	bindings map[slang.Symbol]*binding
+}
+
+func (s *scope) lookup(symbol slang.Symbol) *binding {
+	for ; s != nil; s = s.outer {
+		if b, exists := s.bindings[symbol]; exists {
+			return b
+		}
+	}
+	return nil
+}
+
+type linter struct {
+	file        string
+	globals     *slang.Env
+	diagnostics []Diagnostic
+}
+
+func (l *linter) report(n *parser.Node, severity Severity, code, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		File:     l.file,
+		Start:    n.Start,
+		End:      n.End,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// global returns the value of symbol in the global environment.
+func (l *linter) global(symbol slang.Symbol) (slang.LangType, bool) {
+	if l.globals == nil {
+		return nil, false
+	}
+	value, err := l.globals.Get(symbol)
+	return value, err == nil
+}
+
+// forms returns the children of n that are not trivia.
+func forms(n *parser.Node) []*parser.Node {
+	var children []*parser.Node
+	for _, child := range n.Children {
+		if !child.IsTrivia() {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func symbolOf(n *parser.Node) (slang.Symbol, bool) {
+	symbol, isSymbol := n.Form().(slang.Symbol)
+	return symbol, isSymbol
+}
+
+func isSpecialForm(symbol slang.Symbol) bool {
+	for _, form := range slang.SpecialForms {
+		if form == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// bind binds symbol in s, warning if it shadows a builtin.
+func (l *linter) bind(s *scope, symbol slang.Symbol, n *parser.Node, kind string, arity int) *binding {
+	if _, isGlobal := l.global(symbol); isGlobal {
+		l.report(n, Warning, ShadowedBuiltin, "%s '%s' shadows a builtin", kind, symbol)
+	}
+	b := &binding{node: n, kind: kind, arity: arity}
+	s.bindings[symbol] = b
+	return b
+}
+
+// collectDefines binds the symbols defined by the define forms of a body before the body is walked,
+// so procedures can refer to definitions that follow them.
+func (l *linter) collectDefines(body []*parser.Node, s *scope) {
+	for _, form := range body {
+		items := forms(form)
+		if form.Kind != parser.NodeList || len(items) < 2 {
+			continue
+		}
+		if op, _ := symbolOf(items[0]); op != "define" {
+			continue
+		}
+		symbol, isSymbol := symbolOf(items[1])
+		if !isSymbol {
+			continue
+		}
+		l.bind(s, symbol, items[1], "Definition", definedArity(items[2:]))
+	}
+}
+
+// definedArity returns the number of arguments of the procedure defined by the operands following
+// the symbol of a define form, or -1 if no procedure is defined.
+func definedArity(operands []*parser.Node) int {
+	if len(operands) >= 2 && operands[0].Kind == parser.NodeVector {
+		return len(forms(operands[0]))
+	}
+	if len(operands) == 1 && isLambda(operands[0]) {
+		if items := forms(operands[0]); len(items) >= 2 && items[1].Kind == parser.NodeVector {
+			return len(forms(items[1]))
+		}
+	}
+	return -1
+}
+
+// unused warns about the unused bindings of a procedure scope. Symbols starting with '_' are meant
+// to be unused.
+func (l *linter) unused(s *scope) {
+	for symbol, b := range s.bindings {
+		if !b.used && !strings.HasPrefix(string(symbol), "_") {
+			l.report(b.node, Warning, UnusedBinding, "%s '%s' is never used", b.kind, symbol)
+		}
+	}
+}
+
+// form lints a form evaluated in scope s. self is the binding of the procedure whose body contains
+// the form, used to find recursive calls; tail is true if the form is in tail position of that body.
+func (l *linter) form(n *parser.Node, s *scope, self *binding, tail bool) {
+	switch n.Kind {
+	case parser.NodeAtom:
+		if symbol, isSymbol := symbolOf(n); isSymbol {
+			l.reference(n, symbol, s)
+		}
+	case parser.NodeVector:
+		for _, item := range forms(n) {
+			l.form(item, s, self, false)
+		}
+	case parser.NodeMap:
+		// keys are not evaluated
+		for i, item := range forms(n) {
+			if i%2 == 1 {
+				l.form(item, s, self, false)
+			}
+		}
+	case parser.NodeList:
+		l.list(n, s, self, tail)
+	}
+}
+
+// reference marks the binding of symbol as used, or reports it if it is undefined.
+func (l *linter) reference(n *parser.Node, symbol slang.Symbol, s *scope) {
+	if b := s.lookup(symbol); b != nil {
+		b.used = true
+		return
+	}
+	if _, isGlobal := l.global(symbol); !isGlobal {
+		l.report(n, Error, UndefinedSymbol, "Symbol '%s' is undefined", symbol)
+	}
+}
+
+func (l *linter) list(n *parser.Node, s *scope, self *binding, tail bool) {
+	items := forms(n)
+	if len(items) == 0 {
+		return
+	}
+
+	head := items[0]
+	op, isSymbol := symbolOf(head)
+	if isSymbol && isSpecialForm(op) {
+		l.specialForm(n, op, items[1:], s, self, tail)
+		return
+	}
+	if !isSymbol && head.Kind != parser.NodeList {
+		l.report(n, Error, NotApplicable, "'%s' is not applicable", head)
+	}
+
+	for _, item := range items {
+		l.form(item, s, self, false)
+	}
+	if isSymbol {
+		l.call(n, op, len(items)-1, s, self, tail)
+	}
+}
+
+// call checks the number of arguments of a procedure application and whether it is a recursive
+// call outside of tail position.
+func (l *linter) call(n *parser.Node, op slang.Symbol, nargs int, s *scope, self *binding, tail bool) {
+	b := s.lookup(op)
+	if b != nil && b == self && !tail {
+		l.report(n, Warning, NonTailRecursion,
+			"Recursive call to %s is not in tail position and grows the stack", op)
+	}
+
+	required, variadic := -1, false
+	if b != nil {
+		required = b.arity
+	} else if value, isGlobal := l.global(op); isGlobal {
+		switch t := value.(type) {
+		case slang.Subroutine:
+			if t.Params != nil {
+				required, variadic = t.Arity()
+			}
+		case slang.Lambda:
+			required = len(t.Params())
+		}
+	}
+	if required < 0 {
+		return
+	}
+
+	if variadic && nargs < required {
+		l.report(n, Error, ArityMismatch,
+			"Incorrect number of arguments to %s - expected at least %d, got %d", op, required, nargs)
+	} else if !variadic && nargs != required {
+		l.report(n, Error, ArityMismatch,
+			"Incorrect number of arguments to %s - expected %d, got %d", op, required, nargs)
+	}
+}
+
+// specialForm lints a special form with the rules the evaluator applies to it.
+func (l *linter) specialForm(n *parser.Node, op slang.Symbol, operands []*parser.Node, s *scope,
+	self *binding, tail bool) {
+
+	switch op {
+	case "quote":
+		if len(operands) != 1 {
+			l.report(n, Error, MalformedForm, "Invalid form for quote - expected 1 operand, got %d", len(operands))
+		}
+	case "if":
+		if len(operands) < 2 || len(operands) > 3 {
+			l.report(n, Error, MalformedForm, "Invalid form for if - expected 2 or 3 operands, got %d", len(operands))
+		}
+		for i, operand := range operands {
+			l.form(operand, s, self, tail && i > 0)
+		}
+	case "begin":
+		if len(operands) < 1 {
+			l.report(n, Error, MalformedForm, "Invalid form for begin - expected at least 1 operand")
+		}
+		for i, operand := range operands {
+			l.form(operand, s, self, tail && i == len(operands)-1)
+		}
+	case "lambda":
+		l.lambda(n, operands, s, nil)
+	case "define":
+		l.define(n, operands, s, self)
+	case "with-open":
+		l.withOpen(n, operands, s, self)
+	case "deftest":
+		l.deftest(n, operands, s)
+	case "testing":
+		if len(operands) < 2 || operands[0].Kind != parser.NodeAtom {
+			l.report(n, Error, MalformedForm, "Invalid form for testing - expected a description and a body")
+		} else if _, isStr := operands[0].Form().(slang.Str); !isStr {
+			l.report(operands[0], Error, MalformedForm, "First operand of testing must be a string")
+		}
+		for _, operand := range operands {
+			l.form(operand, s, self, false)
+		}
+	case "is", "assert=":
+		if op == "is" && (len(operands) < 1 || len(operands) > 2) {
+			l.report(n, Error, MalformedForm, "Invalid form for is - expected 1 or 2 operands, got %d", len(operands))
+		} else if op == "assert=" && len(operands) != 2 {
+			l.report(n, Error, MalformedForm, "Invalid form for assert= - expected 2 operands, got %d", len(operands))
+		}
+		for _, operand := range operands {
+			l.form(operand, s, self, false)
+		}
+	case "are":
+		l.are(n, operands, s, self)
+	case "for-all":
+		l.forAll(n, operands, s, self)
+	case "trace", "untrace":
+		for _, operand := range operands {
+			if symbol, isSymbol := symbolOf(operand); isSymbol {
+				l.reference(operand, symbol, s)
+			} else {
+				l.report(operand, Error, MalformedForm, "Arguments to %s must be symbols", op)
+			}
+		}
+	}
+}
+
+func (l *linter) define(n *parser.Node, operands []*parser.Node, s *scope, self *binding) {
+	if len(operands) < 1 {
+		l.report(n, Error, MalformedForm, "Invalid form for define - expected a symbol")
+		return
+	}
+	symbol, isSymbol := symbolOf(operands[0])
+	if !isSymbol {
+		l.report(operands[0], Error, MalformedForm, "First operand of define must be a symbol")
+		return
+	}
+
+	// definitions outside of a body, like in a branch of if, are bound when they are reached
+	b, exists := s.bindings[symbol]
+	if !exists {
+		b = l.bind(s, symbol, operands[0], "Definition", definedArity(operands[1:]))
+	}
+
+	switch {
+	case len(operands) >= 3:
+		l.procedure(operands[1], operands[2:], s, b)
+	case len(operands) == 2 && isLambda(operands[1]):
+		// a lambda bound to symbol may call itself
+		l.lambda(operands[1], forms(operands[1])[1:], s, b)
+	case len(operands) == 2:
+		l.form(operands[1], s, self, false)
+	}
+}
+
+func (l *linter) deftest(n *parser.Node, operands []*parser.Node, s *scope) {
+	if len(operands) < 2 {
+		l.report(n, Error, MalformedForm, "Invalid form for deftest - expected a name and a body")
+		return
+	}
+	symbol, isSymbol := symbolOf(operands[0])
+	if !isSymbol {
+		l.report(operands[0], Error, MalformedForm, "First operand of deftest must be a symbol")
+		return
+	}
+	if _, exists := s.bindings[symbol]; !exists {
+		l.bind(s, symbol, operands[0], "Test", -1).used = true // tests are run by name
+	}
+
+	body := operands[1:]
+	inner := &scope{outer: s, bindings: map[slang.Symbol]*binding{}}
+	l.collectDefines(body, inner)
+	for _, form := range body {
+		l.form(form, inner, nil, false)
+	}
+	l.unused(inner)
+}
+
+// are lints an are form. The template is linted with the parameters bound; the arguments replace
+// them, so they are linted where the form is.
+func (l *linter) are(n *parser.Node, operands []*parser.Node, s *scope, self *binding) {
+	if len(operands) < 2 || operands[0].Kind != parser.NodeVector {
+		l.report(n, Error, MalformedForm, "Invalid form for are - expected parameters and an expression")
+		return
+	}
+	params := forms(operands[0])
+	inner := &scope{outer: s, bindings: map[slang.Symbol]*binding{}}
+	for _, param := range params {
+		if symbol, isSymbol := symbolOf(param); isSymbol {
+			l.bind(inner, symbol, param, "Parameter", -1)
+		} else {
+			l.report(param, Error, MalformedForm, "Parameter %s must be a symbol", param)
+		}
+	}
+	l.form(operands[1], inner, self, false)
+	l.unused(inner)
+
+	args := operands[2:]
+	if len(params) > 0 && len(args)%len(params) != 0 {
+		l.report(n, Error, MalformedForm,
+			"Invalid form for are - %d arguments are not a multiple of %d parameters", len(args), len(params))
+	}
+	for _, arg := range args {
+		l.form(arg, s, self, false)
+	}
+}
+
+func isLambda(n *parser.Node) bool {
+	items := forms(n)
+	if n.Kind != parser.NodeList || len(items) == 0 {
+		return false
+	}
+	op, _ := symbolOf(items[0])
+	return op == "lambda"
+}
+
+func (l *linter) lambda(n *parser.Node, operands []*parser.Node, s *scope, self *binding) {
+	if len(operands) < 2 {
+		l.report(n, Error, MalformedForm, "Invalid form for lambda - expected parameters and a body")
+		return
+	}
+	l.procedure(operands[0], operands[1:], s, self)
+}
+
+func (l *linter) withOpen(n *parser.Node, operands []*parser.Node, s *scope, self *binding) {
+	if len(operands) < 2 {
+		l.report(n, Error, MalformedForm, "Invalid form for with-open - expected a binding and a body")
+		return
+	}
+	pair := forms(operands[0])
+	symbol, isSymbol := slang.Symbol(""), false
+	if operands[0].Kind == parser.NodeVector && len(pair) == 2 {
+		symbol, isSymbol = symbolOf(pair[0])
+	}
+	if !isSymbol {
+		l.report(operands[0], Error, MalformedForm,
+			"First operand of with-open must be a vector of a symbol and a port")
+		return
+	}
+	l.form(pair[1], s, self, false)
+
+	// the body is not in tail position; the port is closed after it is evaluated
+	inner := &scope{outer: s, bindings: map[slang.Symbol]*binding{}}
+	l.bind(inner, symbol, pair[0], "Binding", -1)
+	body := operands[1:]
+	l.collectDefines(body, inner)
+	for _, form := range body {
+		l.form(form, inner, self, false)
+	}
+}
+
+func (l *linter) forAll(n *parser.Node, operands []*parser.Node, s *scope, self *binding) {
+	if len(operands) < 2 || operands[0].Kind != parser.NodeVector || len(forms(operands[0]))%2 != 0 {
+		l.report(n, Error, MalformedForm, "Invalid form for for-all - expected a vector of symbols and generators and a body")
+		return
+	}
+
+	// generators are evaluated in the enclosing scope; the body is not in tail position
+	inner := &scope{outer: s, bindings: map[slang.Symbol]*binding{}}
+	bindings := forms(operands[0])
+	for i := 0; i < len(bindings); i += 2 {
+		l.form(bindings[i+1], s, self, false)
+		if symbol, isSymbol := symbolOf(bindings[i]); isSymbol {
+			l.bind(inner, symbol, bindings[i], "Binding", -1)
+		} else {
+			l.report(bindings[i], Error, MalformedForm, "Binding %s must be a symbol", bindings[i])
+		}
+	}
+	body := operands[1:]
+	l.collectDefines(body, inner)
+	for _, form := range body {
+		l.form(form, inner, self, false)
+	}
+	l.unused(inner)
+}
+
+// procedure lints the parameters and body of a lambda or procedure definition. self is the binding
+// of the procedure, or nil for anonymous lambdas.
+func (l *linter) procedure(params *parser.Node, body []*parser.Node, s *scope, self *binding) {
+	inner := &scope{outer: s, bindings: map[slang.Symbol]*binding{}}
+	if params.Kind != parser.NodeVector {
+		l.report(params, Error, MalformedForm, "Parameters must be a vector")
+	} else {
+		for _, param := range forms(params) {
+			if symbol, isSymbol := symbolOf(param); isSymbol {
+				l.bind(inner, symbol, param, "Parameter", -1)
+			} else {
+				l.report(param, Error, MalformedForm, "Parameter %s must be a symbol", param)
+			}
+		}
+	}
+
+	l.collectDefines(body, inner)
+	for i, form := range body {
+		l.form(form, inner, self, i == len(body)-1)
+	}
+	l.unused(inner)
+}
@@ -0,0 +1,111 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+// testGlobals returns an environment with a few documented subroutines.
+func testGlobals() *slang.Env {
+	env := slang.MakeEnv(nil)
+	noop := func(args ...slang.LangType) (slang.LangType, error) { return nil, nil }
+	params := func(names ...string) slang.Vector {
+		vec := slang.Vector{}
+		for _, name := range names {
+			vec = append(vec, slang.Symbol(name))
+		}
+		return vec
+	}
+	env.UseSubrPackage("test", map[string]func(...slang.LangType) (slang.LangType, error){
+		"+":       noop,
+		"-":       noop,
+		"=":       noop,
+		"nth":     noop,
+		"println": noop,
+		"list":    noop,
+	}, map[string]slang.SubrDoc{
+		"+":   {Params: params("&", "xs")},
+		"-":   {Params: params("x", "&", "xs")},
+		"=":   {Params: params("x", "y")},
+		"nth": {Params: params("seq", "n")},
+	})
+	return &env
+}
+
+var lintTests = []struct {
+	name  string
+	input string
+	want  []string
+}{
+	{"clean", "(define inc [x] (+ x 1))\n(inc 2)", nil},
+	{"undefined symbol", "(println (frobnicate 1))", []string{"1:11 error undefined-symbol Symbol 'frobnicate' is undefined"}},
+	{"defined later", "(define f [] (g))\n(define g [] 1)", nil},
+	{"undefined in branch", "(if (= 1 2) missing 0)", []string{"1:13 error undefined-symbol Symbol 'missing' is undefined"}},
+	{"map keys", "{key value}", []string{"1:6 error undefined-symbol Symbol 'value' is undefined"}},
+	{"quoted", "'(a b c)", nil},
+	{"deftest", "(deftest t\n  (define x 1)\n  (testing \"x\" (is (= x 1) \"one\")))", nil},
+	{"malformed is", "(deftest t (is) (assert= 1))", []string{
+		"1:12 error malformed-form Invalid form for is - expected 1 or 2 operands, got 0",
+		"1:17 error malformed-form Invalid form for assert= - expected 2 operands, got 1"}},
+	{"are", "(are [x y] (= x y) 1 1 2)\n(are [x] (= z 1) 1)", []string{
+		"1:1 error malformed-form Invalid form for are - 3 arguments are not a multiple of 2 parameters",
+		"2:7 warning unused-binding Parameter 'x' is never used",
+		"2:13 error undefined-symbol Symbol 'z' is undefined"}},
+	{"for-all", "(for-all [x missing y 1] (= x x))\n(for-all [1] 2)", []string{
+		"1:13 error undefined-symbol Symbol 'missing' is undefined",
+		"1:21 warning unused-binding Binding 'y' is never used",
+		"2:1 error malformed-form Invalid form for for-all - expected a vector of symbols and generators and a body"}},
+	{"trace", "(define f [] 1)\n(trace f missing 1)", []string{
+		"2:10 error undefined-symbol Symbol 'missing' is undefined",
+		"2:18 error malformed-form Arguments to trace must be symbols"}},
+	{"subroutine arity", "(nth [1 2])", []string{"1:1 error arity-mismatch Incorrect number of arguments to nth - expected 2, got 1"}},
+	{"variadic arity", "(- )\n(- 1 2 3)", []string{"1:1 error arity-mismatch Incorrect number of arguments to - - expected at least 1, got 0"}},
+	{"undocumented arity", "(list)", nil},
+	{"lambda arity", "(define f (lambda [a b] (+ a b)))\n(f 1)", []string{"2:1 error arity-mismatch Incorrect number of arguments to f - expected 2, got 1"}},
+	{"parameter arity unknown", "(define f [g] (g 1 2 3))", nil},
+	{"if operands", "(if true 1 2 3)", []string{"1:1 error malformed-form Invalid form for if - expected 2 or 3 operands, got 4"}},
+	{"lambda body", "(lambda [x])", []string{"1:1 error malformed-form Invalid form for lambda - expected parameters and a body"}},
+	{"define symbol", "(define \"x\" 1)", []string{"1:9 error malformed-form First operand of define must be a symbol"}},
+	{"parameters", "(lambda [x 1] x)", []string{"1:12 error malformed-form Parameter 1 must be a symbol"}},
+	{"with-open", "(with-open [1 2] 3)", []string{"1:12 error malformed-form First operand of with-open must be a vector of a symbol and a port"}},
+	{"not applicable", "(1 2)", []string{"1:1 error not-applicable '1' is not applicable"}},
+	{"shadowed builtin", "(define f [list] list)", []string{"1:12 warning shadowed-builtin Parameter 'list' shadows a builtin"}},
+	{"unused parameter", "(define f [a b _c] a)", []string{"1:14 warning unused-binding Parameter 'b' is never used"}},
+	{"unused local", "(define f [a] (define g 1) a)", []string{"1:23 warning unused-binding Definition 'g' is never used"}},
+	{"tail recursion", "(define loop [n] (if (= n 0) n (loop (- n 1))))", nil},
+	{"tail recursion in begin", "(define loop [n] (begin n (loop n)))", nil},
+	{
+		"non-tail recursion",
+		"(define sum [n] (if (= n 0) 0 (+ n (sum (- n 1)))))",
+		[]string{"1:36 warning non-tail-recursion Recursive call to sum is not in tail position and grows the stack"},
+	},
+	{"recursion in argument of lambda", "(define f [n] ((lambda [] (f n))))", nil},
+	{
+		"syntax errors",
+		"(+ 1))\n(nth)\n\"unterminated",
+		[]string{"1:6 error syntax-error Unexpected ')'", "3:2 error syntax-error Unterminated string"},
+	},
+}
+
+func TestSource(t *testing.T) {
+	for _, test := range lintTests {
+		var got []string
+		for _, d := range Source("test", test.input, testGlobals()) {
+			got = append(got, fmt.Sprintf("%s %s %s %s", d.Start, d.Severity, d.Code, d.Message))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	diagnostics := Source("test.sl", "\n  (nth 1)", testGlobals())
+	want := "test.sl:2:3: error: Incorrect number of arguments to nth - expected 2, got 1 (arity-mismatch)"
+	if len(diagnostics) != 1 || diagnostics[0].String() != want {
+		t.Errorf("\n%s:\n\tgot %v\n\texp %q", "TestDiagnosticString", diagnostics, want)
+	}
+}
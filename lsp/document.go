@@ -0,0 +1,263 @@
+package lsp
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+// document is a text document opened by the client.
+type document struct {
+	uri     string
+	version int
+	text    string
+	root    *parser.Node // nil if the text does not parse
+	// definitions are the top-level definitions of the last text that parsed, used for completion
+	// while the text is being edited
+	definitions []definition
+}
+
+// name returns the file name of the document for messages, or its URI if it is not a file.
+func (d *document) name() string {
+	if u, err := url.Parse(d.uri); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	return d.uri
+}
+
+// update replaces the text of the document and parses it.
+func (d *document) update(version int, text string) {
+	d.version, d.text = version, text
+	root, err := parser.ParseCST(d.name(), text)
+	if err != nil {
+		d.root = nil
+		return
+	}
+	d.root = root
+	d.definitions = bindings(root)
+}
+
+// position converts a byte offset of the text to an LSP position.
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	lineStart := strings.LastIndexByte(d.text[:offset], '\n') + 1
+	return Position{
+		Line:      strings.Count(d.text[:offset], "\n"),
+		Character: utf16Len(d.text[lineStart:offset]),
+	}
+}
+
+// offset converts an LSP position to a byte offset of the text. Positions past the end of a line
+// are moved to the end of the line.
+func (d *document) offset(pos Position) int {
+	start := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(d.text[start:], '\n')
+		if next < 0 {
+			return len(d.text)
+		}
+		start += next + 1
+	}
+	units := 0
+	for i, r := range d.text[start:] {
+		if units >= pos.Character || r == '\n' {
+			return start + i
+		}
+		units += utf16Len(string(r))
+	}
+	return len(d.text)
+}
+
+// span returns the range of a node.
+func (d *document) span(n *parser.Node) Range {
+	return Range{Start: d.position(n.Start.Offset), End: d.position(n.End.Offset)}
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode s.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if r >= 0x10000 {
+			n++ // surrogate pair
+		}
+	}
+	return n
+}
+
+// definition is a symbol bound by define, a parameter or with-open.
+type definition struct {
+	symbol slang.Symbol
+	node   *parser.Node // the symbol where it is bound
+	form   *parser.Node // the define form, or nil for parameters
+	params *parser.Node // parameter vector if a procedure is defined
+	doc    string
+}
+
+// describe returns the name, parameters and docstring of the definition in the format of the doc
+// primitive.
+func (def definition) describe() string {
+	description := string(def.symbol)
+	if def.params != nil {
+		description += " " + def.params.String()
+	}
+	if def.doc != "" {
+		description += "\n  " + def.doc
+	}
+	return description
+}
+
+// forms returns the children of n that are not trivia.
+func forms(n *parser.Node) []*parser.Node {
+	var children []*parser.Node
+	for _, child := range n.Children {
+		if !child.IsTrivia() {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func symbolOf(n *parser.Node) (slang.Symbol, bool) {
+	symbol, isSymbol := n.Form().(slang.Symbol)
+	return symbol, isSymbol
+}
+
+// operator returns the symbol at the head of a list node, or "".
+func operator(n *parser.Node) slang.Symbol {
+	items := forms(n)
+	if n.Kind != parser.NodeList || len(items) == 0 {
+		return ""
+	}
+	op, _ := symbolOf(items[0])
+	return op
+}
+
+// defineOf returns the definition made by a define form.
+func defineOf(n *parser.Node) (definition, bool) {
+	items := forms(n)
+	if operator(n) != "define" || len(items) < 2 {
+		return definition{}, false
+	}
+	symbol, isSymbol := symbolOf(items[1])
+	if !isSymbol {
+		return definition{}, false
+	}
+
+	def := definition{symbol: symbol, node: items[1], form: n}
+	body := items[2:]
+	if len(body) == 1 && operator(body[0]) == "lambda" {
+		body = forms(body[0])[1:]
+	}
+	if len(body) >= 2 && body[0].Kind == parser.NodeVector {
+		def.params = body[0]
+		if doc, isStr := body[1].Form().(slang.Str); isStr && len(body) > 2 {
+			def.doc = string(doc)
+		}
+	}
+	return def, true
+}
+
+// bindings returns the definitions n makes visible to the forms inside it: the parameters of
+// lambdas and procedure definitions, the name bound by with-open and the definitions of bodies. For
+// file nodes, these are the top-level definitions.
+func bindings(n *parser.Node) []definition {
+	var defs []definition
+	items := forms(n)
+	body := items
+	switch {
+	case n.Kind == parser.NodeFile:
+	case operator(n) == "lambda" && len(items) > 1:
+		defs = append(defs, params(items[1])...)
+		body = items[2:]
+	case operator(n) == "define" && len(items) > 3:
+		defs = append(defs, params(items[2])...)
+		body = items[3:]
+	case operator(n) == "with-open" && len(items) > 1:
+		if pair := forms(items[1]); len(pair) == 2 {
+			if symbol, isSymbol := symbolOf(pair[0]); isSymbol {
+				defs = append(defs, definition{symbol: symbol, node: pair[0]})
+			}
+		}
+		body = items[2:]
+	default:
+		return nil
+	}
+
+	for _, form := range body {
+		if def, isDefine := defineOf(form); isDefine {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// params returns the parameters of a parameter vector as definitions.
+func params(vec *parser.Node) []definition {
+	var defs []definition
+	if vec.Kind != parser.NodeVector {
+		return nil
+	}
+	for _, param := range forms(vec) {
+		if symbol, isSymbol := symbolOf(param); isSymbol {
+			defs = append(defs, definition{symbol: symbol, node: param})
+		}
+	}
+	return defs
+}
+
+// path returns the nodes containing offset, from the root to the innermost node. An offset at the
+// end of a node, like a cursor just after a symbol, is contained by the node.
+func path(root *parser.Node, offset int) []*parser.Node {
+	nodes := []*parser.Node{root}
+	for n := root; ; {
+		var next *parser.Node
+		for _, child := range n.Children {
+			if !child.IsTrivia() && child.Start.Offset <= offset && offset <= child.End.Offset {
+				next = child
+			}
+		}
+		if next == nil {
+			return nodes
+		}
+		nodes = append(nodes, next)
+		n = next
+	}
+}
+
+// visible returns the definitions visible at the innermost node of a path, innermost first.
+func visible(nodes []*parser.Node) []definition {
+	var defs []definition
+	for i := len(nodes) - 1; i >= 0; i-- {
+		defs = append(defs, bindings(nodes[i])...)
+	}
+	return defs
+}
+
+// symbolAt returns the symbol node at offset and the definitions visible to it.
+func (d *document) symbolAt(offset int) (*parser.Node, slang.Symbol, []definition) {
+	if d.root == nil {
+		return nil, "", nil
+	}
+	nodes := path(d.root, offset)
+	n := nodes[len(nodes)-1]
+	symbol, isSymbol := symbolOf(n)
+	if n.Kind != parser.NodeAtom || !isSymbol {
+		return nil, "", nil
+	}
+	return n, symbol, visible(nodes)
+}
+
+// lookup returns the innermost definition of symbol.
+func lookup(defs []definition, symbol slang.Symbol) (definition, bool) {
+	for _, def := range defs {
+		if def.symbol == symbol {
+			return def, true
+		}
+	}
+	return definition{}, false
+}
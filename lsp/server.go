@@ -0,0 +1,371 @@
+// Package lsp implements a Language Server Protocol server for slang. The server speaks JSON-RPC over
+// a reader and writer, like the standard input and output of `slang lsp`, and provides diagnostics
+// from the parser and linter, go-to-definition, hover documentation, completion, document symbols
+// and formatting.
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/format"
+	"github.com/zachorosz/slang/lint"
+)
+
+// Server is a language server. Use NewServer to construct a Server.
+type Server struct {
+	globals   *slang.Env
+	conn      *conn
+	documents map[string]*document
+	shutdown  bool
+	handlers  map[string]func(params json.RawMessage) (interface{}, error)
+}
+
+// NewServer constructs a Server. Symbols defined in globals, such as the primitive packages, are
+// known to every document; globals may be nil.
+func NewServer(globals *slang.Env) *Server {
+	s := &Server{globals: globals, documents: map[string]*document{}}
+	s.handlers = map[string]func(json.RawMessage) (interface{}, error){
+		"initialize":                  s.initialize,
+		"initialized":                 ignore,
+		"shutdown":                    s.stop,
+		"textDocument/didOpen":        s.didOpen,
+		"textDocument/didChange":      s.didChange,
+		"textDocument/didClose":       s.didClose,
+		"textDocument/definition":     s.definition,
+		"textDocument/hover":          s.hover,
+		"textDocument/completion":     s.completion,
+		"textDocument/documentSymbol": s.documentSymbol,
+		"textDocument/formatting":     s.formatting,
+	}
+	return s
+}
+
+// Serve reads requests and notifications from r and writes responses and notifications to w until
+// the client sends exit or closes r. An error is returned if the client exits without requesting a
+// shutdown first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		} else if rpcErr, isRPCErr := err.(*responseError); isRPCErr {
+			if err := s.conn.reply(json.RawMessage("null"), nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("Exit without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a message to its handler and replies to requests.
+func (s *Server) handle(msg *message) error {
+	handler, exists := s.handlers[msg.Method]
+	if !msg.isRequest() {
+		// notifications without handlers, like $/cancelRequest, are ignored
+		if exists {
+			handler(msg.Params)
+		}
+		return nil
+	}
+
+	if !exists {
+		return s.conn.reply(msg.ID, nil, &responseError{Code: methodNotFound,
+			Message: fmt.Sprintf("Method '%s' is not supported", msg.Method)})
+	}
+	if s.shutdown {
+		return s.conn.reply(msg.ID, nil, &responseError{Code: invalidRequest, Message: "Server is shut down"})
+	}
+	result, err := handler(msg.Params)
+	return s.conn.reply(msg.ID, result, err)
+}
+
+// decode unmarshals the parameters of a request.
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func ignore(json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(json.RawMessage) (interface{}, error) {
+	result := InitializeResult{Capabilities: ServerCapabilities{
+		TextDocumentSync:           SyncFull,
+		DefinitionProvider:         true,
+		HoverProvider:              true,
+		CompletionProvider:         struct{}{},
+		DocumentSymbolProvider:     true,
+		DocumentFormattingProvider: true,
+	}}
+	result.ServerInfo.Name = "slang"
+	return result, nil
+}
+
+func (s *Server) stop(json.RawMessage) (interface{}, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+// document returns an open document.
+func (s *Server) document(uri string) (*document, error) {
+	d, exists := s.documents[uri]
+	if !exists {
+		return nil, fmt.Errorf("Document %s is not open", uri)
+	}
+	return d, nil
+}
+
+func (s *Server) didOpen(raw json.RawMessage) (interface{}, error) {
+	var params DidOpenTextDocumentParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d := &document{uri: params.TextDocument.URI}
+	d.update(params.TextDocument.Version, params.TextDocument.Text)
+	s.documents[d.uri] = d
+	return nil, s.publishDiagnostics(d)
+}
+
+func (s *Server) didChange(raw json.RawMessage) (interface{}, error) {
+	var params DidChangeTextDocumentParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil || len(params.ContentChanges) == 0 {
+		return nil, err
+	}
+	d.update(params.TextDocument.Version, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	return nil, s.publishDiagnostics(d)
+}
+
+func (s *Server) didClose(raw json.RawMessage) (interface{}, error) {
+	var params DidCloseTextDocumentParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	delete(s.documents, params.TextDocument.URI)
+	// clear the diagnostics of the closed document
+	return nil, s.conn.notify("textDocument/publishDiagnostics",
+		PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+// publishDiagnostics sends the syntax errors and lint diagnostics of a document.
+func (s *Server) publishDiagnostics(d *document) error {
+	var diagnostics []Diagnostic
+	if d.root != nil {
+		diagnostics = s.diagnostics(d, lint.Node(d.name(), d.root, s.globals))
+	} else {
+		diagnostics = s.diagnostics(d, lint.Source(d.name(), d.text, s.globals))
+	}
+	return s.conn.notify("textDocument/publishDiagnostics",
+		PublishDiagnosticsParams{URI: d.uri, Version: d.version, Diagnostics: diagnostics})
+}
+
+func (s *Server) diagnostics(d *document, found []lint.Diagnostic) []Diagnostic {
+	diagnostics := make([]Diagnostic, len(found))
+	for i, diagnostic := range found {
+		severity := SeverityWarning
+		if diagnostic.Severity == lint.Error {
+			severity = SeverityError
+		}
+		diagnostics[i] = Diagnostic{
+			Range:    Range{Start: d.position(diagnostic.Start.Offset), End: d.position(diagnostic.End.Offset)},
+			Severity: severity,
+			Code:     diagnostic.Code,
+			Source:   "slang",
+			Message:  diagnostic.Message,
+		}
+	}
+	return diagnostics
+}
+
+func (s *Server) definition(raw json.RawMessage) (interface{}, error) {
+	var params TextDocumentPositionParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	_, symbol, defs := d.symbolAt(d.offset(params.Position))
+	def, defined := lookup(defs, symbol)
+	if !defined {
+		return nil, nil // builtins and undefined symbols have no location
+	}
+	return Location{URI: d.uri, Range: d.span(def.node)}, nil
+}
+
+func (s *Server) hover(raw json.RawMessage) (interface{}, error) {
+	var params TextDocumentPositionParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	n, symbol, defs := d.symbolAt(d.offset(params.Position))
+	if n == nil {
+		return nil, nil
+	}
+	description := s.describe(symbol, defs)
+	if description == "" {
+		return nil, nil
+	}
+	span := d.span(n)
+	return Hover{Contents: codeBlock(description), Range: &span}, nil
+}
+
+// describe returns the documentation of symbol: its parameters and docstring if it is a procedure
+// defined in the document or a builtin procedure, or the usage of a special form.
+func (s *Server) describe(symbol slang.Symbol, defs []definition) string {
+	if def, defined := lookup(defs, symbol); defined {
+		return def.describe()
+	}
+	if doc, isSpecialForm := slang.SpecialFormDocs[symbol]; isSpecialForm {
+		return doc
+	}
+	if s.globals == nil {
+		return ""
+	}
+	value, err := s.globals.Get(symbol)
+	if err != nil {
+		return ""
+	}
+	if doc, err := slang.Doc(value); err == nil {
+		return string(doc)
+	}
+	return string(symbol)
+}
+
+func codeBlock(s string) MarkupContent {
+	return MarkupContent{Kind: "markdown", Value: "```\n" + s + "\n```"}
+}
+
+func (s *Server) completion(raw json.RawMessage) (interface{}, error) {
+	var params TextDocumentPositionParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := d.offset(params.Position)
+	prefix := d.text[strings.LastIndexAny(d.text[:offset], " \t\r\n()[]{}'\";")+1 : offset]
+	defs := d.definitions
+	if d.root != nil {
+		defs = visible(path(d.root, offset))
+	}
+
+	items := []CompletionItem{}
+	seen := map[slang.Symbol]bool{}
+	add := func(symbol slang.Symbol, kind int, detail string) {
+		if seen[symbol] || !strings.HasPrefix(string(symbol), prefix) {
+			return
+		}
+		seen[symbol] = true
+		items = append(items, CompletionItem{Label: string(symbol), Kind: kind, Detail: detail})
+	}
+	for _, def := range defs {
+		if def.params != nil {
+			add(def.symbol, CompletionFunction, def.params.String())
+		} else {
+			add(def.symbol, CompletionVariable, "")
+		}
+	}
+	for _, form := range slang.SpecialForms {
+		add(form, CompletionKeyword, "special form")
+	}
+	if s.globals != nil {
+		for _, symbol := range slang.Apropos(s.globals, prefix) {
+			value, _ := s.globals.Get(symbol.(slang.Symbol))
+			if params, err := slang.Arglist(value); err == nil {
+				add(symbol.(slang.Symbol), CompletionFunction, params.String())
+			} else {
+				add(symbol.(slang.Symbol), CompletionVariable, "")
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items, nil
+}
+
+func (s *Server) documentSymbol(raw json.RawMessage) (interface{}, error) {
+	var params DocumentParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if d.root == nil {
+		return nil, nil
+	}
+
+	symbols := []DocumentSymbol{}
+	for _, def := range bindings(d.root) {
+		symbol := DocumentSymbol{
+			Name:           string(def.symbol),
+			Kind:           SymbolVariable,
+			Range:          d.span(def.form),
+			SelectionRange: d.span(def.node),
+		}
+		if def.params != nil {
+			symbol.Kind, symbol.Detail = SymbolFunction, def.params.String()
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+func (s *Server) formatting(raw json.RawMessage) (interface{}, error) {
+	var params DocumentParams
+	if err := decode(raw, &params); err != nil {
+		return nil, err
+	}
+	d, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(d.name(), d.text)
+	if err != nil {
+		return nil, err
+	}
+	edits := []TextEdit{}
+	if formatted != d.text {
+		edits = append(edits, TextEdit{
+			Range:   Range{Start: d.position(0), End: d.position(len(d.text))},
+			NewText: formatted,
+		})
+	}
+	return edits, nil
+}
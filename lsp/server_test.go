@@ -0,0 +1,348 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zachorosz/slang"
+)
+
+// testClient is an in-process JSON-RPC client connected to a Server by pipes.
+type testClient struct {
+	t        *testing.T
+	conn     *conn
+	messages chan *message
+	served   chan error
+	nextID   int
+}
+
+func startServer(t *testing.T) *testClient {
+	t.Helper()
+	globals := slang.MakeEnv(nil)
+	globals.UseSubrPackage("test", map[string]func(...slang.LangType) (slang.LangType, error){
+		"+":       func(args ...slang.LangType) (slang.LangType, error) { return nil, nil },
+		"println": func(args ...slang.LangType) (slang.LangType, error) { return nil, nil },
+	}, map[string]slang.SubrDoc{
+		"+": {Params: slang.Vector{slang.Symbol("&"), slang.Symbol("xs")}, Doc: "Returns the sum of xs."},
+	})
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &testClient{
+		t:        t,
+		conn:     newConn(clientIn, clientOut),
+		messages: make(chan *message, 100),
+		served:   make(chan error, 1),
+	}
+	go func() {
+		c.served <- NewServer(&globals).Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	// read on a separate goroutine so notifications never block the server
+	go func() {
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	return c
+}
+
+// receive returns the next message from the server.
+func (c *testClient) receive() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for the server")
+	}
+	return nil
+}
+
+// call sends a request and decodes the result of its response into result.
+func (c *testClient) call(method string, params, result interface{}) *responseError {
+	c.t.Helper()
+	c.nextID++
+	id, _ := json.Marshal(c.nextID)
+	encoded, _ := json.Marshal(params)
+	if err := c.conn.write(&message{ID: id, Method: method, Params: encoded}); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		msg := c.receive()
+		if string(msg.ID) != string(id) {
+			continue // notifications are checked with expect
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("%s: cannot decode result %s: %s", method, msg.Result, err)
+		}
+		return nil
+	}
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// expect returns the parameters of the next notification of method.
+func (c *testClient) expect(method string, params interface{}) {
+	c.t.Helper()
+	for {
+		msg := c.receive()
+		if msg.Method == method {
+			json.Unmarshal(msg.Params, params)
+			return
+		}
+	}
+}
+
+const testURI = "file:///project/square.sl"
+
+const testSource = `(define square [x]
+  "Returns x multiplied by itself."
+  (* x x))
+(define unused-param [a b] a)
+(square 3)
+`
+
+// open starts a server and opens testSource, returning the published diagnostics.
+func open(t *testing.T) (*testClient, PublishDiagnosticsParams) {
+	c := startServer(t)
+	var result InitializeResult
+	if err := c.call("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Capabilities.HoverProvider || result.Capabilities.TextDocumentSync != SyncFull {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp hover and full sync", "initialize", result.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "slang", Version: 1, Text: testSource},
+	})
+	var diagnostics PublishDiagnosticsParams
+	c.expect("textDocument/publishDiagnostics", &diagnostics)
+	return c, diagnostics
+}
+
+func at(line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: testURI},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c, published := open(t)
+	want := []Diagnostic{
+		{Range{Position{2, 3}, Position{2, 4}}, SeverityError, "undefined-symbol", "slang", "Symbol '*' is undefined"},
+		{Range{Position{3, 24}, Position{3, 25}}, SeverityWarning, "unused-binding", "slang", "Parameter 'b' is never used"},
+	}
+	if published.URI != testURI || !reflect.DeepEqual(published.Diagnostics, want) {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp %+v", "didOpen", published, want)
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "(+ 1\n  ]"}},
+	})
+	c.expect("textDocument/publishDiagnostics", &published)
+	want = []Diagnostic{{Range{Position{1, 2}, Position{1, 3}}, SeverityError, "syntax-error", "slang", "Unexpected ']'"}}
+	if published.Version != 2 || !reflect.DeepEqual(published.Diagnostics, want) {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp %+v", "didChange", published, want)
+	}
+
+	c.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}})
+	c.expect("textDocument/publishDiagnostics", &published)
+	if len(published.Diagnostics) != 0 {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp diagnostics to be cleared", "didClose", published)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	c, _ := open(t)
+	tests := []struct {
+		name string
+		at   TextDocumentPositionParams
+		want *Location
+	}{
+		{"top-level definition", at(4, 3), &Location{testURI, Range{Position{0, 8}, Position{0, 14}}}},
+		{"end of symbol", at(4, 7), &Location{testURI, Range{Position{0, 8}, Position{0, 14}}}},
+		{"parameter", at(2, 5), &Location{testURI, Range{Position{0, 16}, Position{0, 17}}}},
+		{"builtin", at(2, 3), nil},
+		{"not a symbol", at(4, 9), nil},
+	}
+	for _, test := range tests {
+		var got *Location
+		if err := c.call("textDocument/definition", test.at, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("\n%s:\n\tgot %+v\n\texp %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestHover(t *testing.T) {
+	c, _ := open(t)
+	tests := []struct {
+		name string
+		at   TextDocumentPositionParams
+		want string
+	}{
+		{"definition", at(4, 2), "square [x]\n  Returns x multiplied by itself."},
+		{"parameter", at(3, 28), "a"},
+		{"special form", at(0, 3), "(define symbol value) or (define symbol [params...] docstring? body...)\n  Defines symbol in the current environment."},
+	}
+	for _, test := range tests {
+		var got *Hover
+		if err := c.call("textDocument/hover", test.at, &got); err != nil {
+			t.Fatal(err)
+		}
+		if want := "```\n" + test.want + "\n```"; got == nil || got.Contents.Value != want {
+			t.Errorf("\n%s:\n\tgot %+v\n\texp %q", test.name, got, want)
+		}
+	}
+
+	// builtins are documented by the doc primitive
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "(+ 1 2)"}},
+	})
+	var got *Hover
+	if err := c.call("textDocument/hover", at(0, 1), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := "```\n+ [& xs]\n  Returns the sum of xs.\n```"; got == nil || got.Contents.Value != want {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp %q", "builtin", got, want)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c, _ := open(t)
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: testSource + "(define f [sq-param] (+ s))\n(pr"}},
+	})
+
+	labels := func(items []CompletionItem) []string {
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Label)
+		}
+		return got
+	}
+
+	// the document does not parse, so the definitions of the last parse are offered
+	var items []CompletionItem
+	if err := c.call("textDocument/completion", at(6, 3), &items); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := labels(items), []string{"println"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp %v", "builtins", got, want)
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 3},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: testSource + "(define f [sq-param] (+ s))\n"}},
+	})
+	if err := c.call("textDocument/completion", at(5, 25), &items); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := labels(items), []string{"sq-param", "square"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp %v", "in scope", got, want)
+	}
+	if items[1].Kind != CompletionFunction || items[1].Detail != "[x]" {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp procedure with detail [x]", "in scope", items[1])
+	}
+}
+
+func TestDocumentSymbolAndFormatting(t *testing.T) {
+	c, _ := open(t)
+	var symbols []DocumentSymbol
+	if err := c.call("textDocument/documentSymbol", DocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}}, &symbols); err != nil {
+		t.Fatal(err)
+	}
+	want := []DocumentSymbol{
+		{"square", "[x]", SymbolFunction, Range{Position{0, 0}, Position{2, 10}}, Range{Position{0, 8}, Position{0, 14}}},
+		{"unused-param", "[a b]", SymbolFunction, Range{Position{3, 0}, Position{3, 29}}, Range{Position{3, 8}, Position{3, 20}}},
+	}
+	if !reflect.DeepEqual(symbols, want) {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp %+v", "documentSymbol", symbols, want)
+	}
+
+	var edits []TextEdit
+	if err := c.call("textDocument/formatting", DocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}}, &edits); err != nil {
+		t.Fatal(err)
+	}
+	wantEdits := []TextEdit{{
+		Range{Position{0, 0}, Position{5, 0}},
+		strings.Replace(testSource, "(define unused-param [a b] a)", "(define unused-param [a b]\n  a)", 1),
+	}}
+	if !reflect.DeepEqual(edits, wantEdits) {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp %+v", "formatting", edits, wantEdits)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	c := startServer(t)
+	var result interface{}
+	if err := c.call("textDocument/rename", at(0, 0), &result); err == nil || err.Code != methodNotFound {
+		t.Errorf("\n%s:\n\tgot %v\n\texp method not found", "unsupported method", err)
+	}
+	if err := c.call("shutdown", nil, &result); err != nil {
+		t.Fatal(err)
+	}
+	c.notify("exit", nil)
+	if err := <-c.served; err != nil {
+		t.Errorf("\n%s:\n\tgot %s\n\texp no error", "TestShutdown", err)
+	}
+
+	c = startServer(t)
+	c.notify("exit", nil)
+	if err := <-c.served; err == nil {
+		t.Errorf("\n%s:\n\tgot no error\n\texp error for exit without shutdown", "TestShutdown")
+	}
+}
+
+func TestPositions(t *testing.T) {
+	d := &document{text: "a\n\"λ𝄞\" b\n"}
+	tests := []struct {
+		offset int
+		pos    Position
+	}{
+		{0, Position{0, 0}},
+		{2, Position{1, 0}},
+		{5, Position{1, 2}},  // after λ, two bytes and one code unit
+		{9, Position{1, 4}},  // after 𝄞, four bytes and two code units
+		{13, Position{2, 0}}, // end of the text
+	}
+	for _, test := range tests {
+		if got := d.position(test.offset); got != test.pos {
+			t.Errorf("\nposition(%d):\n\tgot %v\n\texp %v", test.offset, got, test.pos)
+		}
+		if got := d.offset(test.pos); got != test.offset {
+			t.Errorf("\noffset(%v):\n\tgot %d\n\texp %d", test.pos, got, test.offset)
+		}
+	}
+	if got := d.offset(Position{0, 10}); got != 1 {
+		t.Errorf("\n%s:\n\tgot %d\n\texp 1", "position past the end of a line", got)
+	}
+}
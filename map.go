@@ -0,0 +1,131 @@
+package slang
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Map is an associative type mapping keys to values. Maps are immutable; Assoc and Dissoc return
+// new copies. Keys must be hashable - nil, booleans, Numbers, Strs, Symbols, Chars or UUIDs - and
+// are kept in insertion order.
+type Map struct {
+	keys   []LangType
+	values []LangType
+	index  map[LangType]int
+}
+
+// hashable returns true if x can be used as a Map key.
+func hashable(x LangType) bool {
+	switch x.(type) {
+	case nil, bool, Number, Str, Symbol, Char, UUID:
+		return true
+	default:
+		return false
+	}
+}
+
+// MakeMap creates a new Map from alternating keys and values. If a key is repeated, the last value
+// is kept.
+// Usage: `(hash-map key value ...)`
+func MakeMap(kvs ...LangType) (Map, error) {
+	if len(kvs)%2 != 0 {
+		return Map{}, fmt.Errorf("Map expects an even number of keys and values")
+	}
+	m := Map{}
+	for i := 0; i < len(kvs); i += 2 {
+		var err error
+		if m, err = m.Assoc(kvs[i], kvs[i+1]); err != nil {
+			return Map{}, err
+		}
+	}
+	return m, nil
+}
+
+// Get returns the value of key and true, or nil and false if key is not present.
+func (m Map) Get(key LangType) (LangType, bool) {
+	if !hashable(key) {
+		return nil, false
+	}
+	i, exists := m.index[key]
+	if !exists {
+		return nil, false
+	}
+	return m.values[i], true
+}
+
+// Assoc - O(n) - returns a new copy of the Map with key mapped to value.
+func (m Map) Assoc(key, value LangType) (Map, error) {
+	if !hashable(key) {
+		return Map{}, fmt.Errorf("%s cannot be used as a map key", repr(key))
+	}
+	assoc := Map{
+		keys:   append([]LangType{}, m.keys...),
+		values: append([]LangType{}, m.values...),
+		index:  make(map[LangType]int, len(m.keys)+1),
+	}
+	for k, i := range m.index {
+		assoc.index[k] = i
+	}
+	if i, exists := assoc.index[key]; exists {
+		assoc.values[i] = value
+		return assoc, nil
+	}
+	assoc.index[key] = len(assoc.keys)
+	assoc.keys = append(assoc.keys, key)
+	assoc.values = append(assoc.values, value)
+	return assoc, nil
+}
+
+// Dissoc - O(n) - returns a new copy of the Map without key.
+func (m Map) Dissoc(key LangType) Map {
+	if _, exists := m.Get(key); !exists {
+		return m
+	}
+	dissoc := Map{index: make(map[LangType]int, len(m.keys)-1)}
+	for i, k := range m.keys {
+		if k != key {
+			dissoc.index[k] = len(dissoc.keys)
+			dissoc.keys = append(dissoc.keys, k)
+			dissoc.values = append(dissoc.values, m.values[i])
+		}
+	}
+	return dissoc
+}
+
+// Keys returns a Vector of the keys of the Map in insertion order.
+func (m Map) Keys() Vector {
+	return append(Vector{}, m.keys...)
+}
+
+// Values returns a Vector of the values of the Map in the order of its keys.
+func (m Map) Values() Vector {
+	return append(Vector{}, m.values...)
+}
+
+// Len returns the number of keys in the Map.
+func (m Map) Len() Number {
+	return Number(len(m.keys))
+}
+
+// String returns the external representation of the Map.
+func (m Map) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(repr(key))
+		b.WriteByte(' ')
+		b.WriteString(repr(m.values[i]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// MapP returns true if object is a Map.
+// Usage: `(map? x)`
+func MapP(x LangType) bool {
+	_, isMap := x.(Map)
+	return isMap
+}
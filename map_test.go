@@ -0,0 +1,54 @@
+package slang
+
+import (
+	"testing"
+)
+
+func mustMap(t *testing.T, kvs ...LangType) Map {
+	t.Helper()
+	m, err := MakeMap(kvs...)
+	if err != nil {
+		t.Fatalf("MakeMap(%v) returned unexpected error %s", kvs, err)
+	}
+	return m
+}
+
+func TestMap(t *testing.T) {
+	m := mustMap(t, Str("b"), Number(2), Str("a"), Number(1), Str("b"), Number(3))
+
+	if got := m.String(); got != `{"b" 3 "a" 1}` {
+		t.Errorf("String() == %s, want {\"b\" 3 \"a\" 1}", got)
+	}
+	if value, exists := m.Get(Str("a")); !exists || value != Number(1) {
+		t.Errorf("Get(\"a\") == %v, %v, want 1, true", value, exists)
+	}
+	if _, exists := m.Get(Vector{}); exists {
+		t.Errorf("Get([]) found a value for an unhashable key")
+	}
+
+	assoc, err := m.Assoc(Symbol("c"), nil)
+	if err != nil {
+		t.Fatalf("Assoc returned unexpected error %s", err)
+	}
+	if !Eq(assoc.Keys(), MakeVector(Str("b"), Str("a"), Symbol("c"))) {
+		t.Errorf("Keys() == %v after Assoc", assoc.Keys())
+	}
+	if m.Len() != 2 {
+		t.Errorf("Assoc modified the original map %v", m)
+	}
+
+	dissoc := assoc.Dissoc(Str("b"))
+	if !Eq(dissoc, mustMap(t, Symbol("c"), nil, Str("a"), Number(1))) {
+		t.Errorf("Dissoc(\"b\") == %v", dissoc)
+	}
+	if !Eq(dissoc.Values(), MakeVector(Number(1), nil)) {
+		t.Errorf("Values() == %v after Dissoc", dissoc.Values())
+	}
+
+	if _, err := m.Assoc(Vector{}, nil); err == nil {
+		t.Errorf("Assoc with an unhashable key did not return an error")
+	}
+	if _, err := MakeMap(Str("a")); err == nil {
+		t.Errorf("MakeMap with an odd number of arguments did not return an error")
+	}
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zachorosz/slang"
+)
+
+// NodeKind is the kind of a Node in a concrete syntax tree.
+type NodeKind int
+
+// enumerated kinds of concrete syntax tree nodes
+const (
+	NodeFile       NodeKind = iota // root of a tree; its children are the top-level forms and trivia
+	NodeList                       // (...)
+	NodeVector                     // [...]
+	NodeMap                        // {...}
+	NodeQuote                      // 'form
+	NodeTagged                     // tagged literal like #inst "..."
+	NodeAtom                       // number, string, character, regular expression or symbol
+	NodeWhitespace                 // spaces, tabs and line endings
+	NodeComment                    // comment from ';' to the end of the line
+)
+
+var nodeKindNames = map[NodeKind]string{
+	NodeFile:       "file",
+	NodeList:       "list",
+	NodeVector:     "vector",
+	NodeMap:        "map",
+	NodeQuote:      "quote",
+	NodeTagged:     "tagged",
+	NodeAtom:       "atom",
+	NodeWhitespace: "whitespace",
+	NodeComment:    "comment",
+}
+
+func (kind NodeKind) String() string {
+	return nodeKindNames[kind]
+}
+
+// Node is a node of a concrete syntax tree. Unlike the forms returned by Parse, a tree keeps
+// comments, whitespace and the exact source of every token, so the source can be reproduced byte
+// for byte with String.
+type Node struct {
+	Kind NodeKind
+	// Text is the source of atoms and trivia, the opening delimiter of lists, vectors and maps, and
+	// the prefix of quotes and tagged literals, like ' or #inst.
+	Text string
+	// Close is the closing delimiter of lists, vectors and maps.
+	Close string
+	// Children are the forms and trivia inside a node, in source order.
+	Children []*Node
+	// Start and End span the source of the node, including its children.
+	Start, End Position
+
+	form slang.LangType
+}
+
+// IsTrivia returns true if the node is whitespace or a comment.
+func (n *Node) IsTrivia() bool {
+	return n.Kind == NodeWhitespace || n.Kind == NodeComment
+}
+
+// Form returns the slang form of the node as it was parsed, or nil for trivia and file nodes.
+func (n *Node) Form() slang.LangType {
+	return n.form
+}
+
+// Forms returns the forms of the children of the node that are not trivia.
+func (n *Node) Forms() []slang.LangType {
+	forms := make([]slang.LangType, 0, len(n.Children))
+	for _, child := range n.Children {
+		if !child.IsTrivia() {
+			forms = append(forms, child.form)
+		}
+	}
+	return forms
+}
+
+// String returns the source of the node.
+func (n *Node) String() string {
+	var b strings.Builder
+	n.writeTo(&b)
+	return b.String()
+}
+
+func (n *Node) writeTo(b *strings.Builder) {
+	b.WriteString(n.Text)
+	for _, child := range n.Children {
+		child.writeTo(b)
+	}
+	b.WriteString(n.Close)
+}
+
+// ParseCST parses a slang input string into a concrete syntax tree rooted at a NodeFile node. The
+// forms of the tree, from Forms, are the forms Parse returns for the same input.
+func ParseCST(name, input string) (*Node, error) {
+	l := lex(name, input)
+	l.trivia = true
+	p := newParser(l)
+
+	root := &Node{Kind: NodeFile, Start: Position{Offset: 0, Line: 1, Column: 1}}
+	for tok := p.next(); tok.typ != tokenEOF; tok = p.next() {
+		child, err := parseNode(p)
+		if err != nil {
+			return nil, err
+		}
+		root.Children = append(root.Children, child)
+	}
+	root.End = p.lexer.position(len(input), *p.current)
+	return root, nil
+}
+
+// parseNode parses the node starting at the current token.
+func parseNode(p *parser) (*Node, error) {
+	tok := p.peek()
+	start, end := tok.span()
+	n := &Node{Text: p.lexer.input[start:end], Start: p.lexer.position(start, *tok)}
+
+	switch tok.typ {
+	case tokenWhitespace:
+		n.Kind = NodeWhitespace
+	case tokenComment:
+		n.Kind = NodeComment
+	case tokenLeftParen:
+		n.Kind = NodeList
+		if err := parseNodeChildren(p, n, tokenRightParen); err != nil {
+			return nil, err
+		}
+		var lst slang.Sequence = slang.List{}
+		for _, form := range n.Forms() {
+			lst = lst.Append(form)
+		}
+		n.form = lst.(slang.List).WithSource(p.source(tok))
+	case tokenLeftBracket:
+		n.Kind = NodeVector
+		if err := parseNodeChildren(p, n, tokenRightBracket); err != nil {
+			return nil, err
+		}
+		n.form = slang.Vector(n.Forms())
+	case tokenLeftBrace:
+		n.Kind = NodeMap
+		if err := parseNodeChildren(p, n, tokenRightBrace); err != nil {
+			return nil, err
+		}
+		form, err := p.makeMap(n.Forms(), start, p.peek())
+		if err != nil {
+			return nil, err
+		}
+		n.form = form
+	case tokenQuote:
+		n.Kind = NodeQuote
+		form, err := parsePrefixedNode(p, n)
+		if err != nil {
+			return nil, err
+		}
+		n.form = slang.MakeList(slang.Symbol("quote"), form)
+	case tokenTag:
+		n.Kind = NodeTagged
+		if _, exists := tagReaders[tok.literal]; !exists {
+			return nil, p.errorAt(tok, fmt.Sprintf("Unknown tag #%s", tok.literal))
+		}
+		form, err := parsePrefixedNode(p, n)
+		if err != nil {
+			return nil, err
+		}
+		if n.form, err = p.readTagged(tok.literal, form, start, p.peek()); err != nil {
+			return nil, err
+		}
+	case tokenNumber, tokenComplexNumber, tokenString, tokenRawString, tokenChar, tokenRegex, tokenSymbol:
+		n.Kind = NodeAtom
+		form, err := parse(p)
+		if err != nil {
+			return nil, err
+		}
+		n.form = form
+	default:
+		// EOF, errors and unexpected closing delimiters are reported by parse
+		_, err := parse(p)
+		return nil, err
+	}
+
+	_, end = p.peek().span()
+	n.End = p.lexer.position(end, *p.peek())
+	return n, nil
+}
+
+// parseNodeChildren parses the children of a list, vector or map node up to the close token.
+func parseNodeChildren(p *parser, n *Node, close tokenType) error {
+	for tok := p.next(); tok.typ != close; tok = p.next() {
+		child, err := parseNode(p)
+		if err != nil {
+			return err
+		}
+		n.Children = append(n.Children, child)
+	}
+	n.Close = p.peek().literal
+	return nil
+}
+
+// parsePrefixedNode parses the trivia and form following the prefix of a quote or tagged literal. It
+// returns the form.
+func parsePrefixedNode(p *parser, n *Node) (slang.LangType, error) {
+	for {
+		p.next()
+		child, err := parseNode(p)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+		if !child.IsTrivia() {
+			return child.form, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+var cstSources = []string{
+	"",
+	"  \n",
+	"; only a comment",
+	"(define (f x) ; trailing comment\n  \"doc \\\"string\\\"\"\n\t[x 'y ' z]) \r\n",
+	"{a 1\n ; comment between entries\n b [#\"\\d+\" `raw\nstring`]}",
+	"#inst  \"2020-01-02\" #uuid \"f81d4fae-7dec-11d0-a765-00a0c91e6bf6\" \\a \\space \\u{41}",
+	"'(1 0x1F -2.5e3 λ)",
+}
+
+func TestCSTRoundTrip(t *testing.T) {
+	// a few units of the benchmark source, cut before a comment so no form is split
+	benchmark := benchmarkSource()
+	benchmark = benchmark[:strings.LastIndex(benchmark[:4096], ";")]
+	for _, source := range append(cstSources, benchmark) {
+		root, err := ParseCST("TestCSTRoundTrip", source)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %s for %q", "TestCSTRoundTrip", err, source)
+			continue
+		}
+		if got := root.String(); got != source {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestCSTRoundTrip", got, source)
+		}
+		forms, _ := Parse("TestCSTRoundTrip", source)
+		if !slang.Eq(slang.Vector(root.Forms()), slang.Vector(forms)) {
+			t.Errorf("\n%s:\n\tgot forms %v\n\texp %v", "TestCSTRoundTrip", root.Forms(), forms)
+		}
+	}
+}
+
+// describe returns the kinds and spans of a node and its children, one per line, indented by depth.
+func describe(n *Node, depth int) string {
+	line := strings.Repeat("  ", depth) + n.Kind.String() + " " + n.Start.String() + "-" + n.End.String() + " " + n.Text + "\n"
+	for _, child := range n.Children {
+		line += describe(child, depth+1)
+	}
+	return line
+}
+
+func TestCSTNodes(t *testing.T) {
+	root, err := ParseCST("TestCSTNodes", "; λ\n(f 'x)")
+	if err != nil {
+		t.Fatalf("ParseCST returned unexpected error %s", err)
+	}
+	want := `file 1:1-2:7 
+  comment 1:1-1:4 ; λ
+  whitespace 1:4-2:1 
+
+  list 2:1-2:7 (
+    atom 2:2-2:3 f
+    whitespace 2:3-2:4  
+    quote 2:4-2:6 '
+      atom 2:5-2:6 x
+`
+	if got := describe(root, 0); got != want {
+		t.Errorf("\n%s:\n\tgot\n%s\n\texp\n%s", "TestCSTNodes", got, want)
+	}
+	if list := root.Children[2]; list.Close != ")" || list.Form().(slang.List).String() != "(f (quote x))" {
+		t.Errorf("\n%s:\n\tgot %q, %v\n\texp ), (f (quote x))", "TestCSTNodes", list.Close, list.Form())
+	}
+}
+
+func TestCSTErrors(t *testing.T) {
+	for _, input := range []string{"(a", "'", "#inst ; comment", "{a}", "\"\\q\"", ")"} {
+		_, err := ParseCST("TestCSTErrors", input)
+		_, parseErr := Parse("TestCSTErrors", input)
+		if err == nil || parseErr == nil || err.Error() != parseErr.Error() {
+			t.Errorf("\n%s:\n\tgot %v for %q\n\texp %v", "TestCSTErrors", err, input, parseErr)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a location in slang source. Lines and columns start at 1; columns count runes, not
+// bytes.
+type Position struct {
+	Offset int `json:"offset"` // byte offset from the start of the input
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// ParseError is returned when input cannot be parsed into slang forms. The invalid input spans from
+// Start up to, but not including, End.
+type ParseError struct {
+	File    string
+	Start   Position
+	End     Position
+	Message string
+	Source  string // text of the line containing Start
+
+	incomplete bool
+}
+
+func (err ParseError) Error() string {
+	return fmt.Sprintf("%s:%s: %s", err.File, err.Start, err.Message)
+}
+
+// Incomplete returns true if the error was caused by input ending in the middle of a form, such as
+// an unbalanced paren or an unterminated string. Appending more input may resolve the error.
+func (err ParseError) Incomplete() bool {
+	return err.incomplete
+}
+
+// Snippet renders the source line of the error with the invalid input underlined by carets:
+//
+//	3 | (define x [1 2)
+//	  |               ^
+//
+// Input spanning several lines is underlined to the end of its first line.
+func (err ParseError) Snippet() string {
+	gutter := fmt.Sprintf("%d", err.Start.Line)
+	width := 1
+	if err.End.Line == err.Start.Line && err.End.Column > err.Start.Column {
+		width = err.End.Column - err.Start.Column
+	} else if err.End.Line > err.Start.Line {
+		width = utf8.RuneCountInString(err.Source) - err.Start.Column + 1
+	}
+
+	// tabs before the error are kept so the carets line up with the source
+	var indent strings.Builder
+	for i, r := range []rune(err.Source) {
+		if i >= err.Start.Column-1 {
+			break
+		}
+		if r == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	return fmt.Sprintf("%s | %s\n%s | %s%s\n", gutter, err.Source, strings.Repeat(" ", len(gutter)),
+		indent.String(), strings.Repeat("^", width))
+}
+
+// IsIncomplete returns true if err is a ParseError caused by incomplete input.
+func IsIncomplete(err error) bool {
+	parseErr, ok := err.(ParseError)
+	return ok && parseErr.Incomplete()
+}
+
+// ErrorList is a list of ParseErrors in the order they occur in the input. It is returned by
+// ParseRecover.
+type ErrorList []ParseError
+
+func (list ErrorList) Error() string {
+	messages := make([]string, len(list))
+	for i, err := range list {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// position returns the Position of a byte offset within the input of l. The line number is counted
+// from tok, which must lie on or near the same lines as offset.
+func (l *lexer) position(offset int, tok token) Position {
+	line := tok.line
+	if offset <= tok.pos {
+		line -= strings.Count(l.input[offset:tok.pos], "\n")
+	} else {
+		line += strings.Count(l.input[tok.pos:offset], "\n")
+	}
+	lineStart := strings.LastIndexByte(l.input[:offset], '\n') + 1
+	return Position{
+		Offset: l.offset + offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(l.input[lineStart:offset]) + 1,
+	}
+}
+
+// sourceLine returns the text of the line containing offset without its line ending.
+func (l *lexer) sourceLine(offset int) string {
+	start := strings.LastIndexByte(l.input[:offset], '\n') + 1
+	end := strings.IndexByte(l.input[offset:], '\n')
+	if end < 0 {
+		end = len(l.input)
+	} else {
+		end += offset
+	}
+	return strings.TrimRight(l.input[start:end], "\r")
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+var errorPositionTests = []struct {
+	input      string
+	start, end Position
+	source     string
+}{
+	{"(λ ]", Position{4, 1, 4}, Position{5, 1, 5}, "(λ ]"},
+	{"\"ok\"\n  \"λλ\\q\"", Position{12, 2, 6}, Position{14, 2, 8}, "  \"λλ\\q\""},
+	{"[1 2\r\n  ~]", Position{8, 2, 3}, Position{9, 2, 4}, "  ~]"},
+	{"{a\n1 b}", Position{0, 1, 1}, Position{7, 2, 5}, "{a"},
+	{"(a", Position{2, 1, 3}, Position{2, 1, 3}, "(a"},
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	for _, test := range errorPositionTests {
+		_, err := Parse("TestParseErrorPositions", test.input)
+		parseErr, ok := err.(ParseError)
+		if !ok {
+			t.Errorf("\n%s:\n\tgot %v for %q\n\texp ParseError", "TestParseErrorPositions", err, test.input)
+			continue
+		}
+		if parseErr.Start != test.start || parseErr.End != test.end || parseErr.Source != test.source {
+			t.Errorf("\n%s:\n\tgot %+v, %+v, %q for %q\n\texp %+v, %+v, %q", "TestParseErrorPositions",
+				parseErr.Start, parseErr.End, parseErr.Source, test.input, test.start, test.end, test.source)
+		}
+	}
+}
+
+var snippetTests = []struct {
+	input   string
+	snippet string
+}{
+	{"(define x [1 2)", "1 | (define x [1 2)\n  |               ^\n"},
+	{"\n\n\t(f \"\\bad\")", "3 | \t(f \"\\bad\")\n  | \t    ^^\n"},
+	{"(λ #\"(\")", "1 | (λ #\"(\")\n  |    ^^^^\n"},
+	{"#uuid\n\"x\"", "1 | #uuid\n  | ^^^^^\n"},
+}
+
+func TestSnippet(t *testing.T) {
+	for _, test := range snippetTests {
+		_, err := Parse("TestSnippet", test.input)
+		parseErr, ok := err.(ParseError)
+		if !ok {
+			t.Errorf("\n%s:\n\tgot %v for %q\n\texp ParseError", "TestSnippet", err, test.input)
+		} else if got := parseErr.Snippet(); got != test.snippet {
+			t.Errorf("\n%s:\n\tgot\n%s\n\texp\n%s", "TestSnippet", got, test.snippet)
+		}
+	}
+}
+
+func TestParseRecover(t *testing.T) {
+	input := strings.Join([]string{
+		"(ok 1)",
+		"(bad ] (nested [x y]) z)",
+		"(ok 2) ~ (ok 3)",
+		"{a} \"\\q\"",
+		")",
+		"(ok 4)",
+		"(unclosed",
+	}, "\n")
+	forms, err := ParseRecover("TestParseRecover", input)
+	if len(forms) != 4 {
+		t.Errorf("\n%s:\n\tgot forms %v\n\texp the 4 ok forms", "TestParseRecover", forms)
+	}
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("\n%s:\n\tgot %v\n\texp ErrorList", "TestParseRecover", err)
+	}
+	want := []string{
+		"TestParseRecover:2:6: Unexpected ']'",
+		"TestParseRecover:3:8: unknown rune '~'",
+		"TestParseRecover:4:1: Map literal must contain an even number of forms",
+		"TestParseRecover:4:6: Invalid escape sequence '\\q'",
+		"TestParseRecover:5:1: Unexpected ')'",
+		"TestParseRecover:7:10: Unexpected EOF",
+	}
+	if got := errs.Error(); got != strings.Join(want, "\n") {
+		t.Errorf("\n%s:\n\tgot\n%s\n\texp\n%s", "TestParseRecover", got, strings.Join(want, "\n"))
+	}
+	if _, err := ParseRecover("TestParseRecover", "(ok)"); err != nil {
+		t.Errorf("\n%s:\n\tgot %v\n\texp nil error", "TestParseRecover", err)
+	}
+}
+
+func TestReaderErrorColumns(t *testing.T) {
+	r := NewReader("TestReaderErrorColumns", strings.NewReader("x\n(a) λ ]\n"))
+	for i := 0; i < 3; i++ {
+		r.Next()
+	}
+	_, err := r.Next()
+	parseErr, ok := err.(ParseError)
+	if !ok || parseErr.Start != (Position{9, 2, 7}) || parseErr.Source != "(a) λ ]" {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp ']' at 2:7 (offset 9)", "TestReaderErrorColumns", err)
+	}
+}
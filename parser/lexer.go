@@ -1,5 +1,6 @@
 // Package parser lexically analyzes and parses slang S-expression input. This package is based on
-// Rob Pike's talk "Lexical Scanning in Go" (https://www.youtube.com/watch?v=HxaD_trXwRE).
+// Rob Pike's talk "Lexical Scanning in Go" (https://www.youtube.com/watch?v=HxaD_trXwRE), with the
+// lexer's state functions run synchronously as the parser pulls tokens rather than in a goroutine.
 package parser
 
 import (
@@ -13,15 +14,24 @@ import (
 const (
 	tokenEOF           tokenType = iota
 	tokenError                   // type emitted when an error occurs
+	tokenIncomplete              // type emitted when input ends before a token is complete
 	tokenLeftParen               // left paren (, open list
 	tokenRightParen              // right paren ), closing list
 	tokenLeftBracket             // left bracket [, open vector
 	tokenRightBracket            // right bracket ], closing vector
+	tokenLeftBrace               // left brace {, open map
+	tokenRightBrace              // right brace }, closing map
 	tokenQuote                   // quote '
 	tokenNumber                  // number
 	tokenComplexNumber           // complex number like 1+2i
 	tokenString                  // string
+	tokenRawString               // raw string between backquotes, escapes are not processed
+	tokenChar                    // character literal like \a or \newline
+	tokenRegex                   // regular expression literal like #"[a-z]+"
+	tokenTag                     // tag of a tagged literal like #inst, without the '#'
 	tokenSymbol                  // symbol
+	tokenWhitespace              // spaces, tabs and line endings; only emitted when keeping trivia
+	tokenComment                 // comment from ';' to the end of the line; only emitted when keeping trivia
 )
 
 const eof = -1
@@ -34,8 +44,24 @@ type tokenType int
 type token struct {
 	typ     tokenType
 	literal string
-	pos     int // starting position within input string
-	line    int // line number
+	start   int // position of the literal within input string
+	pos     int // position just past the literal within input string
+	line    int // line number of pos
+}
+
+// span returns the positions of the token within the input string, including delimiters that are not
+// part of its literal, like the quotes of a string.
+func (t token) span() (start, end int) {
+	switch t.typ {
+	case tokenString, tokenRawString:
+		return t.start - 1, t.pos + 1
+	case tokenRegex:
+		return t.start - 2, t.pos + 1
+	case tokenChar, tokenTag:
+		return t.start - 1, t.pos
+	default:
+		return t.start, t.pos
+	}
 }
 
 func (t token) String() string {
@@ -47,13 +73,17 @@ func (t token) String() string {
 }
 
 type lexer struct {
-	name   string     // arbitrary name used for debugging and/or error reporting
-	input  string     // string being scanned
-	start  int        // start position of a token within input string
-	pos    int        // current position in the input string
-	width  int        // width (size) of last rune read from input
-	line   int        // line number (number of newlines seen)
-	tokens chan token // channel with scanned tokens
+	name    string  // arbitrary name used for debugging and/or error reporting
+	input   string  // string being scanned
+	offset  int     // offset of input within a larger input, for error positions
+	start   int     // start position of a token within input string
+	pos     int     // current position in the input string
+	width   int     // width (size) of last rune read from input
+	line    int     // line number (number of newlines seen)
+	recover bool    // continue lexing after errors instead of stopping
+	trivia  bool    // emit whitespace and comments instead of ignoring them
+	state   stateFn // next state function to run; nil when lexing has stopped
+	tokens  []token // scanned tokens that have not been returned by nextToken
 }
 
 func (l *lexer) next() rune {
@@ -111,52 +141,67 @@ func (l *lexer) acceptRun(valid string) {
 	l.backup()
 }
 
-// emit sends the token currently being anaylzed through the token channel. The state calling emit
+// emit queues the token currently being anaylzed to be returned by nextToken. The state calling emit
 // specifies the type.
 func (l *lexer) emit(t tokenType) {
 	current := l.input[l.start:l.pos]
-	l.tokens <- token{t, current, l.pos, l.line}
+	l.tokens = append(l.tokens, token{t, current, l.start, l.pos, l.line})
 	l.start = l.pos
 }
 
-// errorf emits an error and stops lexing by returning a nil state pointer
+// errorf emits an error and stops lexing by returning a nil state pointer. If the lexer is
+// recovering, lexing continues after the invalid input instead.
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	l.tokens <- token{tokenError, fmt.Sprintf(format, args...), l.pos, l.line}
+	l.tokens = append(l.tokens, token{tokenError, fmt.Sprintf(format, args...), l.start, l.pos, l.line})
+	if l.recover {
+		l.start = l.pos
+		return lexText
+	}
 	return nil
 }
 
-// run scans and lexes input by executing state functions until the next state is nil.
-// The initial state is lexText.
-func (l *lexer) run() {
-	for state := lexText; state != nil; {
-		state = state(l)
-	}
-	// done emitting tokens
-	close(l.tokens)
+// incompletef emits an incomplete token and stops lexing. Incomplete tokens signal that more input
+// could make the token valid, such as a string missing its closing quote.
+func (l *lexer) incompletef(format string, args ...interface{}) stateFn {
+	l.tokens = append(l.tokens, token{tokenIncomplete, fmt.Sprintf(format, args...), l.start, l.pos, l.line})
+	return nil
 }
 
-// nextToken returns the next token to be processed.
+// nextToken returns the next token to be processed. State functions are run on demand until one of
+// them emits a token, so input is only scanned as far as the parser reads. Once lexing has stopped,
+// nextToken keeps returning EOF.
 func (l *lexer) nextToken() token {
-	return <-l.tokens
-}
-
-// flush flushes token output so that the goroutine will exit.
-// call this if there are errors that the lexer can't catch during parsing
-func (l *lexer) flush() {
-	for range l.tokens {
+	for len(l.tokens) == 0 {
+		if l.state == nil {
+			return token{tokenEOF, "", l.pos, l.pos, l.line}
+		}
+		l.state = l.state(l)
+	}
+	tok := l.tokens[0]
+	if len(l.tokens) == 1 {
+		l.tokens = l.tokens[:0] // reuse the backing array for the next tokens
+	} else {
+		l.tokens = l.tokens[1:]
 	}
+	return tok
 }
 
 // lex creates a new scanner for the input string.
 func lex(name, input string) *lexer {
-	l := &lexer{
-		name:   name,
-		input:  input,
-		line:   1,
-		tokens: make(chan token),
+	return lexAt(name, input, 0, 1)
+}
+
+// lexAt creates a new scanner that starts scanning input at pos. The line containing pos is given
+// line number line.
+func lexAt(name, input string, pos, line int) *lexer {
+	return &lexer{
+		name:  name,
+		input: input,
+		start: pos,
+		pos:   pos,
+		line:  line,
+		state: lexText,
 	}
-	go l.run()
-	return l
 }
 
 func lexLeftParen(l *lexer) stateFn {
@@ -179,6 +224,16 @@ func lexRightBracket(l *lexer) stateFn {
 	return lexText
 }
 
+func lexLeftBrace(l *lexer) stateFn {
+	l.emit(tokenLeftBrace)
+	return lexText
+}
+
+func lexRightBrace(l *lexer) stateFn {
+	l.emit(tokenRightBrace)
+	return lexText
+}
+
 func lexQuote(l *lexer) stateFn {
 	l.emit(tokenQuote)
 	return lexText
@@ -194,12 +249,14 @@ Loop:
 		switch r := l.next(); {
 		case r == '\\':
 			// get escaped rune, make sure it is not a EOF or newline
-			if r := l.next(); r != eof && r != '\n' {
-				break
+			switch l.next() {
+			case eof:
+				return l.incompletef("Unterminated string")
+			case '\n':
+				return l.errorf("Unterminated string")
 			}
-			fallthrough
 		case r == eof:
-			return l.errorf("Unterminated string")
+			return l.incompletef("Unterminated string")
 		case r == '"':
 			// backup to not emit string with ending '"'
 			l.backup()
@@ -213,25 +270,124 @@ Loop:
 	return lexText
 }
 
+// lexDispatch lexes a form beginning with '#', which is assumed to be seen already.
+func lexDispatch(l *lexer) stateFn {
+	switch r := l.next(); {
+	case r == '"':
+		return lexRegex
+	case unicode.IsLetter(r):
+		return lexTag
+	case r == eof:
+		return l.incompletef("Unterminated dispatch")
+	default:
+		return l.errorf("unknown dispatch rune %q", r)
+	}
+}
+
+// lexTag lexes the tag of a tagged literal, like #inst. The leading '#' and the first letter of the
+// tag are assumed to be seen already. The '#' is not part of the emitted literal.
+func lexTag(l *lexer) stateFn {
+	for isSymbolic(l.peek()) {
+		l.next()
+	}
+	l.start++ // skip '#'
+	l.emit(tokenTag)
+	return lexText
+}
+
+// lexRegex accepts the pattern of a regular expression literal between two double-quotes. The
+// opening '#"' is assumed to be seen already. Backslashes are kept in the pattern; a backslash only
+// prevents a following '"' from ending the literal.
+func lexRegex(l *lexer) stateFn {
+	l.ignore() // ignore leading '#"'
+	for {
+		switch l.next() {
+		case '\\':
+			if l.next() == eof {
+				return l.incompletef("Unterminated regular expression")
+			}
+		case eof:
+			return l.incompletef("Unterminated regular expression")
+		case '"':
+			l.backup()
+			l.emit(tokenRegex)
+			l.next()
+			l.ignore()
+			return lexText
+		}
+	}
+}
+
+// lexRawString accepts a run of characters between two backquotes. The first backquote is assumed
+// to be seen already. Backslashes are not escapes, so a raw string cannot contain a backquote.
+func lexRawString(l *lexer) stateFn {
+	l.ignore() // ignore leading '`'
+	for {
+		switch l.next() {
+		case eof:
+			return l.incompletef("Unterminated raw string")
+		case '`':
+			l.backup()
+			l.emit(tokenRawString)
+			l.next()
+			l.ignore()
+			return lexText
+		}
+	}
+}
+
+// lexChar lexes a character literal. The leading '\' is assumed to be seen already. The literal is
+// either a single rune or a name, like newline or u{41}, that is terminated by a non-symbolic rune.
+func lexChar(l *lexer) stateFn {
+	l.ignore() // ignore leading '\'
+	if r := l.next(); r == eof {
+		return l.incompletef("Unterminated character literal")
+	} else if !isSymbolic(r) {
+		l.emit(tokenChar)
+		return lexText
+	}
+	for isSymbolic(l.peek()) {
+		l.next()
+	}
+	// code points like u{41} are the only names containing braces
+	if l.input[l.start:l.pos] == "u" && l.accept("{") {
+		l.acceptRun("0123456789abcdefABCDEF")
+		l.accept("}")
+	}
+	l.emit(tokenChar)
+	return lexText
+}
+
 // lexComment lexes a comment beginning with ';'. Tokens from this position to the next newline are
-// ultimately ignored. Comment delimiter ';' is assumed to be seen already.
+// ultimately ignored, unless the lexer keeps trivia. Comment delimiter ';' is assumed to be seen
+// already.
 func lexComment(l *lexer) stateFn {
 	comment := l.input[l.pos:]
 	if index := strings.Index(comment, "\n"); index > -1 {
 		l.pos += index // ';' is at pos, offset to next newline
 	} else {
 		// if no newline, skip to EOF
-		l.pos = len(comment) + 1
+		l.pos = len(l.input)
+	}
+	if l.trivia {
+		l.emit(tokenComment)
+	} else {
+		l.ignore() // ignore comment
 	}
-	l.ignore() // ignore comment
 	return lexText
 }
 
+// lexWhiteSpace lexes a run of whitespace and line endings. The first rune is assumed to be seen
+// already.
 func lexWhiteSpace(l *lexer) stateFn {
-	for isWhiteSpace(l.peek()) {
+	for r := l.peek(); isWhiteSpace(r) || isLineEnding(r); r = l.peek() {
 		l.next()
 	}
-	l.ignore()
+	if l.trivia {
+		l.emit(tokenWhitespace)
+	} else {
+		l.ignore()
+	}
 	return lexText
 }
 
@@ -307,10 +463,7 @@ func lexText(l *lexer) stateFn {
 		case r == eof:
 			l.emit(tokenEOF)
 			return nil // stop state machine
-		case isLineEnding(r):
-			l.ignore()
-			return lexText
-		case isWhiteSpace(r):
+		case isLineEnding(r), isWhiteSpace(r):
 			return lexWhiteSpace
 		case r == '(':
 			return lexLeftParen
@@ -320,10 +473,20 @@ func lexText(l *lexer) stateFn {
 			return lexLeftBracket
 		case r == ']':
 			return lexRightBracket
+		case r == '{':
+			return lexLeftBrace
+		case r == '}':
+			return lexRightBrace
 		case r == '\'':
 			return lexQuote
 		case r == '"':
 			return lexString
+		case r == '`':
+			return lexRawString
+		case r == '#':
+			return lexDispatch
+		case r == '\\':
+			return lexChar
 		case r == ';':
 			return lexComment
 		case r == '+' || r == '-' || ('0' <= r && r <= '9'):
@@ -28,6 +28,17 @@ var lexTests = []struct {
 		token{typ: tokenString, literal: "hello\\n\\\"world\\\""},
 		eofToken,
 	}},
+	{"raw string", "`a\\b\n\"c\"`", []token{
+		token{typ: tokenRawString, literal: "a\\b\n\"c\""},
+		eofToken,
+	}},
+	{"chars", "\\a \\newline \\( \\u{41}", []token{
+		token{typ: tokenChar, literal: "a"},
+		token{typ: tokenChar, literal: "newline"},
+		token{typ: tokenChar, literal: "("},
+		token{typ: tokenChar, literal: "u{41}"},
+		eofToken,
+	}},
 	{"comment", "; this is a comment!", []token{eofToken}},
 	{"comment delimited by newline", "; an empty list\n'()", []token{
 		quoteToken,
@@ -64,6 +75,20 @@ var lexTests = []struct {
 		token{typ: tokenComplexNumber, literal: "1+2i"},
 		eofToken,
 	}},
+	{"trailing comment", "(a) ; no newline", []token{
+		token{typ: tokenLeftParen, literal: "("},
+		token{typ: tokenSymbol, literal: "a"},
+		token{typ: tokenRightParen, literal: ")"},
+		eofToken,
+	}},
+	{"maps and tags", "{\\a #inst \"x\"}", []token{
+		token{typ: tokenLeftBrace, literal: "{"},
+		token{typ: tokenChar, literal: "a"},
+		token{typ: tokenTag, literal: "inst"},
+		token{typ: tokenString, literal: "x"},
+		token{typ: tokenRightBrace, literal: "}"},
+		eofToken,
+	}},
 }
 
 func equal(t1, t2 []token) bool {
@@ -102,3 +127,23 @@ func TestLex(t *testing.T) {
 		}
 	}
 }
+
+func TestLexStopsAfterError(t *testing.T) {
+	l := lex("TestLexStopsAfterError", "a ~ b")
+	for _, typ := range []tokenType{tokenSymbol, tokenError, tokenEOF, tokenEOF} {
+		if tok := l.nextToken(); tok.typ != typ {
+			t.Errorf("\n%s:\n\tgot %v\n\texp token type %d", "TestLexStopsAfterError", tok, typ)
+		}
+	}
+}
+
+func BenchmarkLex(b *testing.B) {
+	input := benchmarkSource()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := lex("BenchmarkLex", input)
+		for l.nextToken().typ != tokenEOF {
+		}
+	}
+}
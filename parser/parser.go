@@ -3,23 +3,17 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/zachorosz/slang"
 )
 
-type ParseError struct {
-	name    string
-	tok     token
-	message string
-}
-
-func (err ParseError) Error() string {
-	return fmt.Sprintf("%s: %s (%d, %d)", err.name, err.message, err.tok.line, err.tok.pos)
-}
-
 type parser struct {
 	lexer   *lexer
 	current *token
+	data    bool // data-only mode; code syntax like quote is rejected
+	depth   int  // number of sequences opened and not yet closed
 }
 
 func (p *parser) next() *token {
@@ -35,6 +29,25 @@ func (p *parser) peek() *token {
 	return p.current
 }
 
+// errorSpan returns a ParseError for the input between the positions start and end, which lie within
+// or next to tok.
+func (p *parser) errorSpan(tok *token, start, end int, message string) ParseError {
+	return ParseError{
+		File:       p.lexer.name,
+		Start:      p.lexer.position(start, *tok),
+		End:        p.lexer.position(end, *tok),
+		Message:    message,
+		Source:     p.lexer.sourceLine(start),
+		incomplete: tok.typ == tokenEOF || tok.typ == tokenIncomplete,
+	}
+}
+
+// errorAt returns a ParseError for tok.
+func (p *parser) errorAt(tok *token, message string) ParseError {
+	start, end := tok.span()
+	return p.errorSpan(tok, start, end, message)
+}
+
 func parseNumber(p *parser) (slang.LangType, error) {
 	tok := p.peek()
 	if n, err := strconv.ParseInt(tok.literal, 0, 64); err == nil {
@@ -42,21 +55,25 @@ func parseNumber(p *parser) (slang.LangType, error) {
 	}
 	n, err := strconv.ParseFloat(tok.literal, 64)
 	if err != nil {
-		return nil, err
+		return nil, p.errorAt(tok, fmt.Sprintf("Invalid number '%s'", tok.literal))
 	}
 	return slang.Number(n), nil
 }
 
-func parseQuote(p *parser) (slang.List, error) {
+func parseQuote(p *parser) (slang.LangType, error) {
+	if p.data {
+		return nil, p.errorAt(p.peek(), "Quote is not allowed in data")
+	}
 	p.next() // throw away quote
 	quoted, err := parse(p)
 	if err != nil {
-		return slang.List{}, err
+		return nil, err
 	}
 	return slang.MakeList(slang.Symbol("quote"), quoted), nil
 }
 
 func parseSequence(p *parser, close tokenType, seq slang.Sequence) (slang.Sequence, error) {
+	p.depth++
 	for tok := p.next(); tok.typ != close; tok = p.next() {
 		form, err := parse(p)
 		if err != nil {
@@ -64,9 +81,166 @@ func parseSequence(p *parser, close tokenType, seq slang.Sequence) (slang.Sequen
 		}
 		seq = seq.Append(form)
 	}
+	p.depth--
 	return seq, nil
 }
 
+// parseMap reads the alternating keys and values of a map literal. Keys are not evaluated, so they
+// must be hashable literals.
+func parseMap(p *parser) (slang.LangType, error) {
+	open := p.peek().start
+	items, err := parseSequence(p, tokenRightBrace, slang.Vector{})
+	if err != nil {
+		return nil, err
+	}
+	return p.makeMap(items.(slang.Vector), open, p.peek())
+}
+
+// makeMap makes the Map of a map literal from its alternating keys and values. The literal starts
+// at position open and ends with the token close.
+func (p *parser) makeMap(kvs slang.Vector, open int, close *token) (slang.LangType, error) {
+	if len(kvs)%2 != 0 {
+		return nil, p.errorSpan(close, open, close.pos, "Map literal must contain an even number of forms")
+	}
+	m, err := slang.MakeMap(kvs...)
+	if err != nil {
+		return nil, p.errorSpan(close, open, close.pos, err.Error())
+	}
+	if int(m.Len())*2 != len(kvs) {
+		return nil, p.errorSpan(close, open, close.pos, "Map literal contains a duplicate key")
+	}
+	return m, nil
+}
+
+// tagReaders convert the string following a tag into a value.
+var tagReaders = map[string]func(slang.Str) (slang.LangType, error){
+	"inst": func(s slang.Str) (slang.LangType, error) { return slang.ParseInst(s) },
+	"uuid": func(s slang.Str) (slang.LangType, error) { return slang.ParseUUID(s) },
+}
+
+// parseTagged reads a tagged literal, a tag like #inst followed by a string.
+func parseTagged(p *parser) (slang.LangType, error) {
+	tag := p.peek()
+	if _, exists := tagReaders[tag.literal]; !exists {
+		return nil, p.errorAt(tag, fmt.Sprintf("Unknown tag #%s", tag.literal))
+	}
+	start, _ := tag.span()
+	p.next()
+	form, err := parse(p)
+	if err != nil {
+		return nil, err
+	}
+	return p.readTagged(tag.literal, form, start, p.peek())
+}
+
+// readTagged converts the form following a tag. The tagged literal starts at position start and
+// ends with the token last.
+func (p *parser) readTagged(tag string, form slang.LangType, start int, last *token) (slang.LangType, error) {
+	_, end := last.span()
+	s, isStr := form.(slang.Str)
+	if !isStr {
+		return nil, p.errorSpan(last, start, end, fmt.Sprintf("#%s expects a string", tag))
+	}
+	value, err := tagReaders[tag](s)
+	if err != nil {
+		return nil, p.errorSpan(last, start, end, err.Error())
+	}
+	return value, nil
+}
+
+// parseString decodes the escape sequences of a string literal: \n, \t, \r, \\, \" and \u{hex}.
+func parseString(p *parser) (slang.LangType, error) {
+	tok := p.peek()
+	literal := tok.literal
+	if !strings.ContainsRune(literal, '\\') {
+		return slang.Str(literal), nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			b.WriteByte(literal[i])
+			continue
+		}
+		escape := i
+		i++
+		switch literal[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\', '"':
+			b.WriteByte(literal[i])
+		case 'u':
+			r, width, ok := decodeCodePoint(literal[i+1:])
+			if !ok {
+				end := i + 1 + strings.IndexByte(literal[i:], '}')
+				if end <= i+1 {
+					end = i + 1
+				}
+				return nil, p.errorSpan(tok, tok.start+escape, tok.start+end, "Invalid unicode escape sequence")
+			}
+			b.WriteRune(r)
+			i += width
+		default:
+			r, width := utf8.DecodeRuneInString(literal[i:])
+			return nil, p.errorSpan(tok, tok.start+escape, tok.start+i+width, fmt.Sprintf("Invalid escape sequence '\\%c'", r))
+		}
+	}
+	return slang.Str(b.String()), nil
+}
+
+// decodeCodePoint decodes a code point written as {hex}. It returns the rune and the number of
+// bytes consumed.
+func decodeCodePoint(s string) (rune, int, bool) {
+	end := strings.IndexByte(s, '}')
+	if !strings.HasPrefix(s, "{") || end < 2 {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseUint(s[1:end], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return 0, 0, false
+	}
+	return rune(n), end + 1, true
+}
+
+// parseChar converts a character literal, either a single rune, a name like newline or a code point
+// like u{41}.
+func parseChar(p *parser) (slang.LangType, error) {
+	tok := p.peek()
+	literal := tok.literal
+	if utf8.RuneCountInString(literal) == 1 {
+		r, _ := utf8.DecodeRuneInString(literal)
+		return slang.Char(r), nil
+	}
+	if c, ok := slang.CharByName(literal); ok {
+		return c, nil
+	}
+	if strings.HasPrefix(literal, "u") {
+		if r, width, ok := decodeCodePoint(literal[1:]); ok && width == len(literal)-1 {
+			return slang.Char(r), nil
+		}
+	}
+	return nil, p.errorAt(tok, fmt.Sprintf("Invalid character literal '\\%s'", literal))
+}
+
+// parseRegex compiles a regular expression literal. Escaped double-quotes are unescaped; every
+// other backslash is passed to the regexp package as is.
+func parseRegex(p *parser) (slang.LangType, error) {
+	tok := p.peek()
+	if p.data {
+		return nil, p.errorAt(tok, "Regular expressions are not allowed in data")
+	}
+	pattern := strings.Replace(tok.literal, `\"`, `"`, -1)
+	re, err := slang.RePattern(slang.Str(pattern))
+	if err != nil {
+		return nil, p.errorAt(tok, err.Error())
+	}
+	return re, nil
+}
+
 func parseSymbol(p *parser) slang.LangType {
 	tok := p.peek()
 	switch tok.literal {
@@ -85,30 +259,54 @@ func parse(p *parser) (slang.LangType, error) {
 	tok := p.peek()
 	switch tok.typ {
 	case tokenEOF:
-		return nil, ParseError{p.lexer.name, *tok, "Unexpected EOF"}
-	case tokenError:
-		return nil, ParseError{p.lexer.name, *tok, tok.literal}
+		return nil, p.errorAt(tok, "Unexpected EOF")
+	case tokenError, tokenIncomplete:
+		return nil, p.errorAt(tok, tok.literal)
 	case tokenLeftParen:
-		return parseSequence(p, tokenRightParen, slang.List{})
+		src := p.source(tok)
+		lst, err := parseSequence(p, tokenRightParen, slang.List{})
+		if err != nil {
+			return nil, err
+		}
+		return lst.(slang.List).WithSource(src), nil
 	case tokenRightParen:
-		return nil, ParseError{p.lexer.name, *tok, fmt.Sprintf("Unexpected '%s'", tok.literal)}
+		return nil, p.errorAt(tok, fmt.Sprintf("Unexpected '%s'", tok.literal))
 	case tokenLeftBracket:
 		return parseSequence(p, tokenRightBracket, slang.Vector{})
 	case tokenRightBracket:
-		return nil, ParseError{p.lexer.name, *tok, fmt.Sprintf("Unexpected '%s'", tok.literal)}
+		return nil, p.errorAt(tok, fmt.Sprintf("Unexpected '%s'", tok.literal))
+	case tokenLeftBrace:
+		return parseMap(p)
+	case tokenRightBrace:
+		return nil, p.errorAt(tok, fmt.Sprintf("Unexpected '%s'", tok.literal))
 	case tokenQuote:
 		return parseQuote(p)
 	case tokenNumber, tokenComplexNumber:
 		return parseNumber(p)
 	case tokenString:
+		return parseString(p)
+	case tokenRawString:
 		return slang.Str(tok.literal), nil
+	case tokenChar:
+		return parseChar(p)
+	case tokenRegex:
+		return parseRegex(p)
+	case tokenTag:
+		return parseTagged(p)
 	case tokenSymbol:
 		return parseSymbol(p), nil
 	default:
-		return nil, ParseError{p.lexer.name, *tok, fmt.Sprintf("Encountered token %s with unknown type", tok.literal)}
+		return nil, p.errorAt(tok, fmt.Sprintf("Encountered token %s with unknown type", tok.literal))
 	}
 }
 
+// source returns the location of a token in the input.
+func (p *parser) source(tok *token) slang.Source {
+	start, _ := tok.span()
+	pos := p.lexer.position(start, *tok)
+	return slang.Source{File: p.lexer.name, Line: pos.Line, Column: pos.Column}
+}
+
 func newParser(l *lexer) *parser {
 	return &parser{
 		lexer: l,
@@ -117,7 +315,59 @@ func newParser(l *lexer) *parser {
 
 // Parse lexes and parses a slang input string
 func Parse(name, input string) ([]slang.LangType, error) {
+	return parseAll(newParser(lex(name, input)))
+}
+
+// ParseData parses a slang input string as data, such as a configuration file. Data may contain
+// literals, symbols, lists, vectors, maps and tagged literals like #inst and #uuid, but not code
+// syntax such as quote or regular expressions.
+func ParseData(name, input string) ([]slang.LangType, error) {
 	p := newParser(lex(name, input))
+	p.data = true
+	return parseAll(p)
+}
+
+// ParseRecover parses a slang input string like Parse, but continues after syntax errors to report
+// every error in the input. It returns the forms that were parsed and, if there were errors, an
+// ErrorList. Parsing resumes after the top-level form containing each error.
+func ParseRecover(name, input string) ([]slang.LangType, error) {
+	l := lex(name, input)
+	l.recover = true
+	p := newParser(l)
+	program := make([]slang.LangType, 0)
+	var errs ErrorList
+	for p.next().typ != tokenEOF {
+		p.depth = 0
+		form, err := parse(p)
+		if err != nil {
+			errs = append(errs, err.(ParseError))
+			p.skipForm()
+			continue
+		}
+		program = append(program, form)
+	}
+	if len(errs) > 0 {
+		return program, errs
+	}
+	return program, nil
+}
+
+// skipForm skips the tokens of the sequences that are still open after an error, so parsing can
+// resume with the next top-level form.
+func (p *parser) skipForm() {
+	for p.depth > 0 {
+		switch p.next().typ {
+		case tokenLeftParen, tokenLeftBracket, tokenLeftBrace:
+			p.depth++
+		case tokenRightParen, tokenRightBracket, tokenRightBrace:
+			p.depth--
+		case tokenEOF:
+			return
+		}
+	}
+}
+
+func parseAll(p *parser) ([]slang.LangType, error) {
 	program := make([]slang.LangType, 0)
 	for p.next().typ != tokenEOF {
 		form, err := parse(p)
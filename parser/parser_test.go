@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"io"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zachorosz/slang"
 )
@@ -177,3 +181,319 @@ func TestParseQuote(t *testing.T) {
 		}
 	}
 }
+
+var incompleteTests = []struct {
+	input      string
+	incomplete bool
+}{
+	{"(", true},
+	{"(define x [1 2", true},
+	{"'", true},
+	{"\"hello", true},
+	{"\"hello\\", true},
+	{"(+ 1 2))", false},
+	{"]", false},
+	{"\"hello\\\n\"", false},
+}
+
+func TestParseIncomplete(t *testing.T) {
+	for _, test := range incompleteTests {
+		_, err := Parse("TestParseIncomplete", test.input)
+		if err == nil {
+			t.Errorf("\n%s:\n\texpected error for %q", "TestParseIncomplete", test.input)
+		} else if got := IsIncomplete(err); got != test.incomplete {
+			t.Errorf("\n%s:\n\tgot %t for %q (%s)\n\texp %t", "TestParseIncomplete", got, test.input, err, test.incomplete)
+		}
+	}
+}
+
+var escapeTests = []struct {
+	input    string
+	expected slang.Str
+}{
+	{`"a\nb"`, slang.Str("a\nb")},
+	{`"\t\r\\"`, slang.Str("\t\r\\")},
+	{`"say \"hi\""`, slang.Str("say \"hi\"")},
+	{`"\u{3bb}\u{1F600}"`, slang.Str("λ😀")},
+	{"`C:\\path\\n`", slang.Str("C:\\path\\n")},
+	{"`multi\nline \"raw\"`", slang.Str("multi\nline \"raw\"")},
+}
+
+func TestParseEscapes(t *testing.T) {
+	for _, test := range escapeTests {
+		got, err := Parse("TestParseEscapes", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseEscapes", err)
+			continue
+		}
+		if got[0] != test.expected {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestParseEscapes", got[0], test.expected)
+		}
+	}
+}
+
+var invalidEscapeTests = []struct {
+	input string
+	err   string
+}{
+	{`"abc\q"`, "TestParseInvalidEscapes:1:5: Invalid escape sequence '\\q'"},
+	{"\"a\nb\\u{zz}\"", "TestParseInvalidEscapes:2:2: Invalid unicode escape sequence"},
+	{`"\u{110000}"`, "TestParseInvalidEscapes:1:2: Invalid unicode escape sequence"},
+	{`\bogus`, "TestParseInvalidEscapes:1:1: Invalid character literal '\\bogus'"},
+}
+
+func TestParseInvalidEscapes(t *testing.T) {
+	for _, test := range invalidEscapeTests {
+		_, err := Parse("TestParseInvalidEscapes", test.input)
+		if err == nil {
+			t.Errorf("\n%s:\n\texpected error for %s", "TestParseInvalidEscapes", test.input)
+		} else if _, ok := err.(ParseError); !ok || err.Error() != test.err {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestParseInvalidEscapes", err, test.err)
+		}
+	}
+}
+
+var charTests = []struct {
+	input    string
+	expected slang.Char
+}{
+	{`\a`, slang.Char('a')},
+	{`\λ`, slang.Char('λ')},
+	{`\(`, slang.Char('(')},
+	{`\\`, slang.Char('\\')},
+	{`\newline`, slang.Char('\n')},
+	{`\space`, slang.Char(' ')},
+	{`\u{41}`, slang.Char('A')},
+}
+
+func TestParseChar(t *testing.T) {
+	for _, test := range charTests {
+		got, err := Parse("TestParseChar", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseChar", err)
+			continue
+		}
+		if got[0] != test.expected {
+			t.Errorf("\n%s:\n\tgot %v\n\texp %v", "TestParseChar", got[0], test.expected)
+		}
+	}
+}
+
+var regexTests = []struct {
+	input   string
+	pattern string
+}{
+	{`#"\d+"`, `\d+`},
+	{`#"a\"b"`, `a"b`},
+	{`#"\\"`, `\\`},
+}
+
+func TestParseRegex(t *testing.T) {
+	for _, test := range regexTests {
+		got, err := Parse("TestParseRegex", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseRegex", err)
+			continue
+		}
+		re, ok := got[0].(slang.Regex)
+		if !ok {
+			t.Errorf("\n%s:\n\tgot %T\n\texp Regex", "TestParseRegex", got[0])
+		} else if re.Regexp.String() != test.pattern {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestParseRegex", re.Regexp.String(), test.pattern)
+		}
+	}
+
+	_, err := Parse("TestParseRegex", `(re-find #"(unclosed" s)`)
+	if _, ok := err.(ParseError); !ok {
+		t.Errorf("\n%s:\n\tgot %v\n\texp ParseError for invalid regular expression", "TestParseRegex", err)
+	}
+	if _, err := Parse("TestParseRegex", `#"open`); !IsIncomplete(err) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp incomplete ParseError", "TestParseRegex", err)
+	}
+}
+
+var mapTests = []struct {
+	input    string
+	expected string
+}{
+	{`{}`, `{}`},
+	{`{"b" 1 a [x]}`, `{"b" 1 a [x]}`},
+	{`{\a {1 nil}}`, `{\a {1 nil}}`},
+}
+
+func TestParseMap(t *testing.T) {
+	for _, test := range mapTests {
+		got, err := Parse("TestParseMap", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseMap", err)
+			continue
+		}
+		m, ok := got[0].(slang.Map)
+		if !ok {
+			t.Errorf("\n%s:\n\tgot %T\n\texp Map", "TestParseMap", got[0])
+		} else if m.String() != test.expected {
+			t.Errorf("\n%s:\n\tgot %s\n\texp %s", "TestParseMap", m, test.expected)
+		}
+	}
+}
+
+var taggedTests = []struct {
+	input    string
+	expected slang.LangType
+}{
+	{`#inst "1985-04-12T23:20:50.52Z"`, slang.Inst{Time: time.Date(1985, 4, 12, 23, 20, 50, 520000000, time.UTC)}},
+	{`#inst "1985-04-12"`, slang.Inst{Time: time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC)}},
+	{`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`, slang.UUID{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0,
+		0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}},
+}
+
+func TestParseTagged(t *testing.T) {
+	for _, test := range taggedTests {
+		got, err := Parse("TestParseTagged", test.input)
+		if err != nil {
+			t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseTagged", err)
+			continue
+		}
+		if !slang.Eq(got[0], test.expected) {
+			t.Errorf("\n%s:\n\tgot %v\n\texp %v", "TestParseTagged", got[0], test.expected)
+		}
+	}
+}
+
+var dataErrorTests = []struct {
+	input string
+	err   string
+}{
+	{`{a}`, "TestParseDataErrors:1:1: Map literal must contain an even number of forms"},
+	{`{a 1 a 2}`, "TestParseDataErrors:1:1: Map literal contains a duplicate key"},
+	{`{[] 1}`, "TestParseDataErrors:1:1: [] cannot be used as a map key"},
+	{`#date "x"`, "TestParseDataErrors:1:1: Unknown tag #date"},
+	{`#inst 1`, "TestParseDataErrors:1:1: #inst expects a string"},
+	{`#uuid "abc"`, "TestParseDataErrors:1:1: Invalid UUID \"abc\" - expected the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"},
+	{`'a`, "TestParseDataErrors:1:1: Quote is not allowed in data"},
+	{`[#"a"]`, "TestParseDataErrors:1:2: Regular expressions are not allowed in data"},
+}
+
+func TestParseDataErrors(t *testing.T) {
+	for _, test := range dataErrorTests {
+		_, err := ParseData("TestParseDataErrors", test.input)
+		if err == nil {
+			t.Errorf("\n%s:\n\texpected error for %s", "TestParseDataErrors", test.input)
+		} else if err.Error() != test.err {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", "TestParseDataErrors", err, test.err)
+		}
+	}
+
+	got, err := ParseData("TestParseDataErrors", `{"port" 80 hosts [a b] started #inst "2020-01-01"}`)
+	if err != nil {
+		t.Errorf("\n%s:\n\tgot unexpected error %+v", "TestParseDataErrors", err)
+	} else if !slang.MapP(got[0]) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp Map", "TestParseDataErrors", got[0])
+	}
+	if _, err := ParseData("TestParseDataErrors", `{a 1`); !IsIncomplete(err) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp incomplete ParseError", "TestParseDataErrors", err)
+	}
+}
+
+// benchmarkSource returns a program of about 4MB using every kind of token.
+func benchmarkSource() string {
+	const unit = `; compute things
+(define (f x) "Doc string with \"escapes\"\n" (if (< x 0x1F) [x 1.5e3 -2] {a 'b "c" \d}))
+(re-find #"\d+" ` + "`raw string`" + `) #inst "2020-01-02" (f -1)
+`
+	return strings.Repeat(unit, 4<<20/len(unit))
+}
+
+func BenchmarkParse(b *testing.B) {
+	input := benchmarkSource()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse("BenchmarkParse", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	input := benchmarkSource()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReader("BenchmarkReader", strings.NewReader(input))
+		for {
+			if _, err := r.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkReaderLongForm reads a single form of many lines, which the Reader reads a line at a time.
+func BenchmarkReaderLongForm(b *testing.B) {
+	input := "(define table [\n" + strings.Repeat("  {\"key\" \"value\" \"n\" 'n} 1.5 ; entry\n", 5000) + "])\n"
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReader("BenchmarkReaderLongForm", strings.NewReader(input))
+		if _, err := r.Next(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFailedParseLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		for _, input := range []string{"(a b c)) d e f", "(\"unterminated", "[1 2 ~ 3]", "{a}"} {
+			if _, err := Parse("TestFailedParseLeavesNoGoroutines", input); err == nil {
+				t.Fatalf("Parse(%q) did not return an error", input)
+			}
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("\n%s:\n\tgot %d goroutines after failed parses\n\texp %d", "TestFailedParseLeavesNoGoroutines", after, before)
+	}
+}
+
+func TestParseListSource(t *testing.T) {
+	input := "(define f [x]\n  (g\t(h x)))\n'(λ (y))"
+	want := []string{"test:1:1", "test:2:3", "test:2:6", "", "test:3:2", "test:3:5"}
+
+	parsed, _ := Parse("test", input)
+	reader := NewReader("test", strings.NewReader(input))
+	fromReader := []slang.LangType{}
+	for form, err := reader.Next(); err == nil; form, err = reader.Next() {
+		fromReader = append(fromReader, form)
+	}
+	root, _ := ParseCST("test", input)
+
+	for name, forms := range map[string][]slang.LangType{"Parse": parsed, "Reader": fromReader, "ParseCST": root.Forms()} {
+		// walk the lists depth first
+		var got []string
+		var walk func(x slang.LangType)
+		walk = func(x slang.LangType) {
+			lst, isList := x.(slang.List)
+			if !isList {
+				return
+			}
+			src, _ := lst.Source()
+			if src.File == "" {
+				got = append(got, "")
+			} else {
+				got = append(got, src.String())
+			}
+			for _, item := range lst.Items() {
+				walk(item)
+			}
+		}
+		for _, form := range forms {
+			walk(form)
+		}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/zachorosz/slang"
+)
+
+// Reader reads slang forms one at a time from an io.Reader. Input is read a line at a time and only
+// the lines of the form being read are buffered, so large files can be read in constant memory.
+// The lines of a form of several lines are lexed once each to find the end of the form, which is
+// then parsed, so long forms are read in linear time.
+type Reader struct {
+	name   string
+	in     *bufio.Reader
+	data   bool            // read forms in data-only mode
+	buf    strings.Builder // buffered lines of input
+	input  string          // contents of buf
+	pos    int             // position of the input in the first line that has not been read as a form
+	line   int             // line number of the start of input
+	offset int             // byte offset of the start of input
+	eof    bool            // in has no more input
+
+	// the tokens of the first unread form are scanned as lines are read, to find its end
+	scanned  int  // position of the input up to which tokens have been scanned
+	started  bool // tokens of the form have been scanned
+	depth    int  // number of lists, vectors and maps the scanned tokens leave open
+	complete bool // the scanned tokens end a form, or are invalid
+}
+
+// NewReader returns a Reader that reads forms from r. name is used in error messages.
+func NewReader(name string, r io.Reader) *Reader {
+	in, ok := r.(*bufio.Reader)
+	if !ok {
+		in = bufio.NewReader(r)
+	}
+	return &Reader{name: name, in: in, line: 1}
+}
+
+// NewDataReader returns a Reader that reads forms from r in data-only mode, like ParseData.
+func NewDataReader(name string, r io.Reader) *Reader {
+	reader := NewReader(name, r)
+	reader.data = true
+	return reader
+}
+
+// Next reads and returns the next form. It returns io.EOF when the input ends between forms. If the
+// input ends in the middle of a form, the returned ParseError is incomplete (see IsIncomplete).
+//
+// Next blocks until a whole form has been read. After a syntax error, the buffered input is
+// discarded and reading resumes at the next line.
+func (r *Reader) Next() (slang.LangType, error) {
+	for {
+		// a form is parsed without scanning it first, until it turns out to span several lines
+		if !r.started || r.complete || r.eof {
+			if form, done, err := r.read(); done {
+				return form, err
+			}
+			// the scanned tokens did not end the form after all; it is parsed again at the end of input
+			r.complete = false
+		}
+		if r.scan(); r.complete {
+			continue
+		}
+		if err := r.readLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// read parses the first unread form of the buffered input. It returns false if more input is needed
+// to read a form.
+func (r *Reader) read() (slang.LangType, bool, error) {
+	form, end, err := r.parseForm()
+	if err == nil && end >= 0 {
+		r.consume(end)
+		return form, true, nil
+	}
+	if err != nil && (!IsIncomplete(err) || r.eof) {
+		r.consume(len(r.input))
+		return nil, true, err
+	}
+	if r.eof {
+		return nil, true, io.EOF
+	}
+	if err == nil {
+		// the input holds no form, only whitespace and comments
+		r.scanned = len(r.input)
+	}
+	return nil, false, nil
+}
+
+// scan lexes the tokens of the input that have not been scanned, until they end a form. A token
+// that is incomplete at the end of the input is scanned again when more input is read.
+func (r *Reader) scan() {
+	if r.complete || r.scanned == len(r.input) {
+		return
+	}
+	l := lexAt(r.name, r.input, r.scanned, r.line)
+	for {
+		tok := l.nextToken()
+		switch tok.typ {
+		case tokenEOF:
+			r.scanned = tok.pos
+			return
+		case tokenIncomplete:
+			r.started = true
+			return
+		case tokenError:
+			// the parser reports the error
+			r.complete = true
+			return
+		case tokenLeftParen, tokenLeftBracket, tokenLeftBrace:
+			r.depth++
+		case tokenRightParen, tokenRightBracket, tokenRightBrace:
+			r.depth--
+			r.complete = r.depth <= 0
+		case tokenQuote, tokenTag:
+			// prefixes of the next form
+		default:
+			r.complete = r.depth == 0
+		}
+		_, r.scanned = tok.span()
+		r.started = true
+		if r.complete {
+			return
+		}
+	}
+}
+
+// parseForm parses the first unread form of the buffered input. It returns the position of the end
+// of the form, or -1 if the input holds no form.
+func (r *Reader) parseForm() (slang.LangType, int, error) {
+	l := lexAt(r.name, r.input, r.pos, r.line)
+	l.offset = r.offset
+	p := newParser(l)
+	p.data = r.data
+
+	if p.next().typ == tokenEOF {
+		return nil, -1, nil
+	}
+	form, err := parse(p)
+	if err != nil {
+		return nil, -1, err
+	}
+	_, end := p.current.span()
+	return form, end, nil
+}
+
+// consume marks the input up to pos as read. Lines before the line containing pos are discarded;
+// that line is kept so errors can show its source. Scanning starts again at pos.
+func (r *Reader) consume(pos int) {
+	lineStart := strings.LastIndexByte(r.input[:pos], '\n') + 1
+	r.line += strings.Count(r.input[:lineStart], "\n")
+	r.offset += lineStart
+	rest := r.input[lineStart:]
+	r.buf.Reset()
+	r.buf.WriteString(rest)
+	r.input = r.buf.String()
+	r.pos = pos - lineStart
+	r.scanned, r.depth, r.started, r.complete = r.pos, 0, false, false
+}
+
+// readLine appends the next line of input to the buffer.
+func (r *Reader) readLine() error {
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF {
+		r.eof = true
+	} else if err != nil {
+		return err
+	}
+	r.buf.WriteString(line)
+	r.input = r.buf.String()
+	return nil
+}
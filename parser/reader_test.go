@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+var readerTests = []struct {
+	name  string
+	input string
+	forms []string
+}{
+	{"empty", "", []string{}},
+	{"blank lines and comments", "\n  ; nothing\n\n", []string{}},
+	{"one form per line", "1\n(+ 1 2)\n", []string{"1", "(+ 1 2)"}},
+	{"many forms per line", `"a" "b\"" [c] #"\d" \x 'y`, []string{`"a"`, `"b\""`, "[c]", `#"\d"`, `\x`, "(quote y)"}},
+	{"multi-line forms", "(define x\n  [1\n   2]) ; done\n`raw\nstring` {a\n1}", []string{"(define x [1 2])", "\"raw\\nstring\"", "{a 1}"}},
+	{"no trailing newline", "(a) b", []string{"(a)", "b"}},
+	{"brackets in tokens", "(a \"(\n)\" ; )\n \\) \\(\n b) c", []string{`(a "(\n)" \) \( b)`, "c"}},
+	{"prefixes", "'\n(a\n b) #inst\n \"2020-01-02T00:00:00Z\" '\n'c", []string{"(quote (a b))", "#inst \"2020-01-02T00:00:00Z\"", "(quote (quote c))"}},
+	{"forms after a multi-line form", "[1\n 2] 3 (4\n) 5\n", []string{"[1 2]", "3", "(4)", "5"}},
+}
+
+func TestReader(t *testing.T) {
+	for _, test := range readerTests {
+		r := NewReader(test.name, strings.NewReader(test.input))
+		got := []string{}
+		for {
+			form, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("\n%s:\n\tgot unexpected error %s", test.name, err)
+			}
+			got = append(got, fmt.Sprint(form))
+		}
+		if strings.Join(got, "|") != strings.Join(test.forms, "|") {
+			t.Errorf("\n%s:\n\tgot %q\n\texp %q", test.name, got, test.forms)
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Errorf("\n%s:\n\tgot %v after EOF\n\texp io.EOF", test.name, err)
+		}
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	r := NewReader("TestReaderErrors", strings.NewReader("1\n2 ) 3\n\n(4\n"))
+	want := []struct {
+		form       slang.LangType
+		err        string
+		incomplete bool
+	}{
+		{form: slang.Number(1)},
+		{form: slang.Number(2)},
+		{err: "TestReaderErrors:2:3: Unexpected ')'"},
+		{err: "TestReaderErrors:5:1: Unexpected EOF", incomplete: true},
+	}
+	for _, w := range want {
+		form, err := r.Next()
+		if w.err == "" && (err != nil || form != w.form) {
+			t.Errorf("\n%s:\n\tgot %v, %v\n\texp %v", "TestReaderErrors", form, err, w.form)
+		} else if w.err != "" && (err == nil || err.Error() != w.err || IsIncomplete(err) != w.incomplete) {
+			t.Errorf("\n%s:\n\tgot %v\n\texp %s (incomplete %t)", "TestReaderErrors", err, w.err, w.incomplete)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("\n%s:\n\tgot %v\n\texp io.EOF", "TestReaderErrors", err)
+	}
+}
+
+// lineReader returns one line per Read and fails once its lines are exhausted.
+type lineReader struct {
+	lines []string
+}
+
+func (r *lineReader) Read(b []byte) (int, error) {
+	if len(r.lines) == 0 {
+		return 0, errors.New("read past the end of the form")
+	}
+	n := copy(b, r.lines[0])
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func TestReaderDoesNotReadAhead(t *testing.T) {
+	r := NewReader("TestReaderDoesNotReadAhead", &lineReader{[]string{"(+ 1\n", "2) 'x\n"}})
+	form, err := r.Next()
+	if err != nil || form.(slang.List).String() != "(+ 1 2)" {
+		t.Errorf("\n%s:\n\tgot %v, %v\n\texp (+ 1 2)", "TestReaderDoesNotReadAhead", form, err)
+	}
+	form, err = r.Next()
+	if err != nil || !slang.Eq(form, slang.MakeList(slang.Symbol("quote"), slang.Symbol("x"))) {
+		t.Errorf("\n%s:\n\tgot %v, %v\n\texp (quote x)", "TestReaderDoesNotReadAhead", form, err)
+	}
+}
+
+func TestDataReader(t *testing.T) {
+	r := NewDataReader("TestDataReader", strings.NewReader("{a 1}\n'b"))
+	if form, err := r.Next(); err != nil || !slang.MapP(form) {
+		t.Errorf("\n%s:\n\tgot %v, %v\n\texp Map", "TestDataReader", form, err)
+	}
+	if _, err := r.Next(); err == nil || IsIncomplete(err) {
+		t.Errorf("\n%s:\n\tgot %v\n\texp syntax error for quote", "TestDataReader", err)
+	}
+}
@@ -0,0 +1,200 @@
+package slang
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Port is a slang stream type wrapping an io.Reader, an io.Writer, or both. Ports are closed by the
+// `with-open` special form.
+type Port struct {
+	name   string
+	reader *bufio.Reader
+	writer io.Writer
+	closer io.Closer
+	forms  FormReader
+}
+
+// FormReader reads forms from an input Port one at a time, like parser.Reader.
+type FormReader interface {
+	Next() (LangType, error)
+}
+
+// Symbols bound to the standard ports by UsePorts.
+const (
+	InSymbol  = Symbol("*in*")
+	OutSymbol = Symbol("*out*")
+	ErrSymbol = Symbol("*err*")
+)
+
+// MakeInputPort makes a Port that reads from r. If r is an io.Closer, closing the Port closes r.
+func MakeInputPort(name string, r io.Reader) *Port {
+	closer, _ := r.(io.Closer)
+	return &Port{name: name, reader: bufio.NewReader(r), closer: closer}
+}
+
+// MakeOutputPort makes a Port that writes to w. If w is an io.Closer, closing the Port closes w.
+func MakeOutputPort(name string, w io.Writer) *Port {
+	closer, _ := w.(io.Closer)
+	return &Port{name: name, writer: w, closer: closer}
+}
+
+func (port *Port) String() string {
+	return fmt.Sprintf("<port %s>", port.name)
+}
+
+// Reader returns the reader of the Port, or nil if it is not an input port.
+func (port *Port) Reader() io.Reader {
+	if port.reader == nil {
+		return nil
+	}
+	return port.reader
+}
+
+// Writer returns the writer of the Port, or nil if it is not an output port.
+func (port *Port) Writer() io.Writer {
+	return port.writer
+}
+
+// FormReader returns the FormReader set for the Port, or nil.
+func (port *Port) FormReader() FormReader {
+	return port.forms
+}
+
+// SetFormReader sets the FormReader that reads forms from the Port. It is kept with the Port so the
+// input it has buffered is not lost between reads, and released when the Port is closed.
+func (port *Port) SetFormReader(forms FormReader) {
+	port.forms = forms
+}
+
+// Close closes the underlying stream of the Port, if it has one.
+func (port *Port) Close() error {
+	port.forms = nil
+	if port.closer == nil {
+		return nil
+	}
+	return port.closer.Close()
+}
+
+// UsePorts defines the standard ports *in*, *out* and *err* in the current frame. Embedders pass
+// their own readers and writers to capture or redirect the I/O of slang programs.
+func (env *Env) UsePorts(in io.Reader, out, err io.Writer) error {
+	ports := map[Symbol]*Port{
+		InSymbol:  {name: string(InSymbol), reader: bufio.NewReader(in)},
+		OutSymbol: {name: string(OutSymbol), writer: out},
+		ErrSymbol: {name: string(ErrSymbol), writer: err},
+	}
+	for symbol, port := range ports {
+		if err := env.Define(symbol, port); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PortP returns true if object is a Port.
+// Usage: `(port? x)`
+func PortP(x LangType) bool {
+	_, isPort := x.(*Port)
+	return isPort
+}
+
+// OpenInput opens a file for reading.
+// Usage: `(open-input path)`
+func OpenInput(path Str) (*Port, error) {
+	f, err := os.Open(string(path))
+	if err != nil {
+		return nil, err
+	}
+	return MakeInputPort(string(path), f), nil
+}
+
+// OpenOutput opens a file for writing. The file is truncated unless append is true.
+// Usage: `(open-output path append?)`
+func OpenOutput(path Str, append bool) (*Port, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(string(path), flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return MakeOutputPort(string(path), f), nil
+}
+
+// ReadLine reads a line from an input port without its line ending. nil is returned at the end of
+// the input.
+// Usage: `(read-line port?)`
+func ReadLine(port *Port) (LangType, error) {
+	if port.reader == nil {
+		return nil, fmt.Errorf("%s is not an input port", port)
+	}
+	line, err := port.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return nil, nil
+	} else if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return Str(strings.TrimRight(line, "\r\n")), nil
+}
+
+// Write writes s to an output port.
+// Usage: `(write port args...)`
+func Write(port *Port, s string) error {
+	if port.writer == nil {
+		return fmt.Errorf("%s is not an output port", port)
+	}
+	_, err := io.WriteString(port.writer, s)
+	return err
+}
+
+// Slurp returns the contents of a file.
+// Usage: `(slurp path)`
+func Slurp(path Str) (Str, error) {
+	b, err := ioutil.ReadFile(string(path))
+	if err != nil {
+		return "", err
+	}
+	return Str(b), nil
+}
+
+// Spit writes content to a file. The file is overwritten unless append is true.
+// Usage: `(spit path content append?)`
+func Spit(path, content Str, append bool) error {
+	port, err := OpenOutput(path, append)
+	if err != nil {
+		return err
+	}
+	if err := Write(port, string(content)); err != nil {
+		port.Close()
+		return err
+	}
+	return port.Close()
+}
+
+// ReadLines returns a Vector of the lines of a file.
+// Usage: `(read-lines path)`
+func ReadLines(path Str) (Vector, error) {
+	port, err := OpenInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer port.Close()
+
+	lines := Vector{}
+	for {
+		line, err := ReadLine(port)
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
@@ -0,0 +1,60 @@
+package slang
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadLine(t *testing.T) {
+	port := MakeInputPort("test", strings.NewReader("a\nb\r\n\nc"))
+	want := []LangType{Str("a"), Str("b"), Str(""), Str("c"), nil, nil}
+	for _, w := range want {
+		got, err := ReadLine(port)
+		if err != nil {
+			t.Fatalf("ReadLine returned unexpected error %s", err)
+		}
+		if got != w {
+			t.Errorf("ReadLine == %v, want %v", got, w)
+		}
+	}
+
+	if _, err := ReadLine(MakeOutputPort("out", &bytes.Buffer{})); err == nil {
+		t.Errorf("ReadLine of an output port returned no error")
+	}
+}
+
+func TestUsePorts(t *testing.T) {
+	env := MakeEnv(nil)
+	out := &bytes.Buffer{}
+	if err := env.UsePorts(strings.NewReader(""), out, out); err != nil {
+		t.Fatalf("UsePorts returned unexpected error %s", err)
+	}
+
+	value, err := env.Get(OutSymbol)
+	if err != nil {
+		t.Fatalf("Get(%s) returned unexpected error %s", OutSymbol, err)
+	}
+	Write(value.(*Port), "captured")
+	if out.String() != "captured" {
+		t.Errorf("*out* wrote %q, want %q", out.String(), "captured")
+	}
+}
+
+type testFormReader struct{}
+
+func (testFormReader) Next() (LangType, error) {
+	return nil, nil
+}
+
+func TestPortFormReader(t *testing.T) {
+	port := MakeInputPort("test", strings.NewReader("1"))
+	port.SetFormReader(testFormReader{})
+	if port.FormReader() == nil {
+		t.Errorf("FormReader() == nil after SetFormReader")
+	}
+	port.Close()
+	if port.FormReader() != nil {
+		t.Errorf("FormReader() == %v after Close, want nil", port.FormReader())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unicode"
 )
 
 // LangType base type to interface with the language types of slang.
@@ -124,8 +125,33 @@ func (n Number) LessThanOrEqualTo(obj Comparable) (bool, error) {
 // Str is a slang string type.
 type Str string
 
+// String returns the string quoted with double-quotes. Quotes, backslashes and control characters
+// are escaped so that the string can be read back by the parser.
 func (s Str) String() string {
-	return string("\"" + s + "\"")
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range string(s) {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
 }
 
 // Plus returns a new, concatenated string.
@@ -172,9 +198,63 @@ func (s Str) Modulo(obj Algebraic) (Algebraic, error) {
 	return nil, fmt.Errorf("Modulo operator is not defined on string")
 }
 
+// SubrDoc documents a Subroutine. Params is a Vector of parameter symbols; a `&` symbol before the
+// last parameter marks the subroutine as variadic.
+type SubrDoc struct {
+	Params Vector
+	Doc    string
+}
+
+// Arity returns the number of required arguments and whether any number of additional arguments
+// are accepted.
+func (doc SubrDoc) Arity() (n int, variadic bool) {
+	for _, param := range doc.Params {
+		if param == Symbol("&") {
+			return n, true
+		}
+		n++
+	}
+	return n, false
+}
+
+// Char is a slang character type holding a single Unicode code point.
+type Char rune
+
+// charNames maps characters to the names used by their literals.
+var charNames = map[Char]string{
+	' ':  "space",
+	'\n': "newline",
+	'\t': "tab",
+	'\r': "return",
+}
+
+// CharByName returns the character with the given literal name.
+func CharByName(name string) (Char, bool) {
+	for c, n := range charNames {
+		if n == name {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the literal representation of the character, such as \a or \newline.
+func (c Char) String() string {
+	if name, ok := charNames[c]; ok {
+		return `\` + name
+	}
+	if unicode.IsControl(rune(c)) {
+		return fmt.Sprintf(`\u{%x}`, rune(c))
+	}
+	return `\` + string(c)
+}
+
 // Subroutine a slang function that is implemented in the host language, Go!
 type Subroutine struct {
 	Func func(...LangType) (LangType, error)
+	Name Symbol
+	SubrDoc
+	traced bool
 }
 
 // Apply applies arguments to the subroutine and returns the evaluation.
@@ -191,25 +271,65 @@ type Lambda struct {
 	params Vector
 	body   List
 	env    Env
+	name   Symbol
+	doc    string
+	source *Source // the form that made the Lambda, if it was read from source
+	traced bool
+
+	analyzedBody analyzed // the analyzed body, if the Lambda was made by analyzed code
+	slots        []Symbol // symbols of the slots of the frames the analyzed body is applied in
+	frame        *Env     // the environment the analyzed body closes over
 }
 
 func (lambda Lambda) String() string {
 	return "<procedure>"
 }
 
+// Params returns the parameter Vector of the Lambda.
+func (lambda Lambda) Params() Vector {
+	return lambda.params
+}
+
+// Name returns the symbol the Lambda was defined as, or an empty symbol for anonymous lambdas.
+func (lambda Lambda) Name() Symbol {
+	return lambda.name
+}
+
+// Source returns the location of the define or lambda form that made the Lambda. It returns false if
+// the form was not read from source.
+func (lambda Lambda) Source() (Source, bool) {
+	if lambda.source == nil {
+		return Source{}, false
+	}
+	return *lambda.source, true
+}
+
+// Doc returns the docstring of the Lambda.
+func (lambda Lambda) Doc() string {
+	return lambda.doc
+}
+
 // MakeLambda makes a new Lambda function with N-arity. When applied, arguments are bound to its
 // environment frame (A.K.A. closure) and the body is evaluated. The evaluation of the final, or
-// only, expression in the body is used as the return value.
-// Usage: `(lambda [params...] body...)`
+// only, expression in the body is used as the return value. If the body has more than one
+// expression and the first is a string, it is used as the docstring.
+// Usage: `(lambda [params...] docstring? body...)`
 func MakeLambda(env Env, params Vector, body List) (Lambda, error) {
 	if body.Len() == 0 {
 		return Lambda{}, fmt.Errorf("Lambda body expected")
 	}
 
+	var doc string
+	if docstring, isStr := body.First().(Str); isStr && body.Len() > 1 {
+		doc = string(docstring)
+		body = body.Rest().(List)
+	}
+
 	return Lambda{
 		params: params,
 		body:   body,
 		env:    env,
+		doc:    doc,
 	}, nil
 }
 
@@ -238,6 +358,13 @@ func StringP(x LangType) bool {
 	return isString
 }
 
+// CharP returns true if object is a character.
+// Usage: `(char? x)`
+func CharP(x LangType) bool {
+	_, isChar := x.(Char)
+	return isChar
+}
+
 // SymbolP returns true if object is a Symbol.
 // Usage: `(symbol? x)`
 func SymbolP(x LangType) bool {
@@ -279,6 +406,22 @@ func Eq(lhs, rhs LangType) bool {
 			}
 		}
 		return true
+	case Map:
+		t2 := rhs.(Map)
+		if t1.Len() != t2.Len() {
+			return false
+		}
+		for i, key := range t1.keys {
+			value, exists := t2.Get(key)
+			if !exists || !Eq(t1.values[i], value) {
+				return false
+			}
+		}
+		return true
+	case Regex:
+		return t1.String() == rhs.(Regex).String()
+	case Inst:
+		return t1.Equal(rhs.(Inst).Time)
 	default:
 		return lhs == rhs
 	}
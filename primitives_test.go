@@ -206,3 +206,22 @@ func TestModNumbers(t *testing.T) {
 		}
 	}
 }
+
+func TestSubrDocArity(t *testing.T) {
+	cases := []struct {
+		params   Vector
+		n        int
+		variadic bool
+	}{
+		{Vector{}, 0, false},
+		{Vector{Symbol("x"), Symbol("y")}, 2, false},
+		{Vector{Symbol("x"), Symbol("&"), Symbol("rest")}, 1, true},
+	}
+
+	for _, c := range cases {
+		n, variadic := SubrDoc{Params: c.params}.Arity()
+		if n != c.n || variadic != c.variadic {
+			t.Errorf("Arity() of %s == (%d, %t), want (%d, %t)", c.params, n, variadic, c.n, c.variadic)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zachorosz/slang"
+)
+
+// Sprint returns the concatenated display representations of args.
+// Usage: `(str args...)`
+func Sprint(args ...slang.LangType) string {
+	var b strings.Builder
+	for _, arg := range args {
+		Fprint(&b, arg, Display)
+	}
+	return b.String()
+}
+
+// Sprintf formats args according to a printf-style format string. Verbs are applied to the Go
+// value that best matches them: integer verbs (%d %b %o %x %X %c) truncate Numbers to integers,
+// floating-point verbs (%e %f %g) use Numbers as floats, %s and %v print the display representation
+// and %q quotes strings and prints other values in the readable representation.
+// Usage: `(format fmt args...)`
+func Sprintf(format string, args ...slang.LangType) (string, error) {
+	values := make([]interface{}, 0, len(args))
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		// skip flags, width and precision to find the verb
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) {
+			return "", fmt.Errorf("Format string ends with an incomplete verb")
+		}
+		verb := format[i]
+		if verb == '%' {
+			continue
+		}
+		if len(values) == len(args) {
+			return "", fmt.Errorf("Missing argument for verb %%%c", verb)
+		}
+		values = append(values, formatValue(verb, args[len(values)]))
+	}
+	if len(values) < len(args) {
+		return "", fmt.Errorf("Too many arguments - format uses %d of %d arguments", len(values), len(args))
+	}
+	return fmt.Sprintf(format, values...), nil
+}
+
+// formatValue converts a slang value to the Go value that matches a format verb.
+func formatValue(verb byte, x slang.LangType) interface{} {
+	switch verb {
+	case 'd', 'b', 'o', 'x', 'X', 'c', 'U':
+		switch t := x.(type) {
+		case slang.Number:
+			return int64(t)
+		case slang.Char:
+			return rune(t)
+		case slang.Str:
+			return string(t)
+		}
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		if n, isNumber := x.(slang.Number); isNumber {
+			return float64(n)
+		}
+	case 's', 'v':
+		return DisplayStr(x)
+	case 'q':
+		if s, isStr := x.(slang.Str); isStr {
+			return string(s)
+		}
+		return verbatim(PrStr(x))
+	case 't':
+		if b, isBool := x.(bool); isBool {
+			return b
+		}
+	}
+	return x
+}
+
+// verbatim is formatted as is by any verb.
+type verbatim string
+
+func (v verbatim) Format(f fmt.State, verb rune) {
+	io.WriteString(f, string(v))
+}
@@ -0,0 +1,39 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/zachorosz/slang"
+)
+
+var sprintfTests = []struct {
+	format string
+	args   []slang.LangType
+	want   string
+	err    bool
+}{
+	{"%d items", []slang.LangType{slang.Number(3)}, "3 items", false},
+	{"%05.2f|%x", []slang.LangType{slang.Number(3.14159), slang.Number(255)}, "03.14|ff", false},
+	{"%s=%v", []slang.LangType{slang.Str("key"), slang.MakeVector(slang.Str("a"))}, "key=[a]", false},
+	{"%q %q", []slang.LangType{slang.Str("a\"b"), slang.MakeList(slang.Str("c"))}, "\"a\\\"b\" (\"c\")", false},
+	{"%c%t 100%%", []slang.LangType{slang.Char('λ'), true}, "λtrue 100%", false},
+	{"%s %s", []slang.LangType{slang.Str("a")}, "", true},
+	{"%s", []slang.LangType{slang.Str("a"), slang.Str("b")}, "", true},
+	{"trailing %", []slang.LangType{}, "", true},
+}
+
+func TestSprintf(t *testing.T) {
+	for _, test := range sprintfTests {
+		got, err := Sprintf(test.format, test.args...)
+		if (err != nil) != test.err || got != test.want {
+			t.Errorf("Sprintf(%q, %v) == %q, %v, want %q", test.format, test.args, got, err, test.want)
+		}
+	}
+}
+
+func TestSprint(t *testing.T) {
+	got := Sprint(slang.Str("n="), slang.Number(1), nil, slang.Char('!'))
+	if want := "n=1nil!"; got != want {
+		t.Errorf("Sprint == %q, want %q", got, want)
+	}
+}
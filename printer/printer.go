@@ -0,0 +1,164 @@
+// Package printer writes the external representation of slang values. Values are printed in one of
+// two modes: Readable output can be read back by the parser, while Display output is meant for
+// humans and prints strings without quotes or escapes.
+package printer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zachorosz/slang"
+)
+
+// Mode selects how values are printed.
+type Mode int
+
+// enumerated printing modes
+const (
+	Readable Mode = iota // round-trips through parser.Parse; used by pr-str
+	Display              // human readable; used by print-str
+)
+
+// cycleMarker is printed in place of a sequence that contains itself.
+const cycleMarker = "<cycle>"
+
+type printer struct {
+	w        *bufio.Writer
+	mode     Mode
+	visiting map[vectorKey]bool // vectors currently being printed, for cycle detection
+}
+
+// Fprint writes the external representation of x to w.
+func Fprint(w io.Writer, x slang.LangType, mode Mode) error {
+	p := &printer{
+		w:        bufio.NewWriter(w),
+		mode:     mode,
+		visiting: map[vectorKey]bool{},
+	}
+	p.print(x)
+	return p.w.Flush()
+}
+
+// Fprintln writes the external representation of x to w followed by a newline.
+func Fprintln(w io.Writer, x slang.LangType, mode Mode) error {
+	if err := Fprint(w, x, mode); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// PrStr returns the readable representation of x.
+// Usage: `(pr-str x)`
+func PrStr(x slang.LangType) string {
+	var b strings.Builder
+	Fprint(&b, x, Readable)
+	return b.String()
+}
+
+// DisplayStr returns the human readable representation of x.
+// Usage: `(print-str x)`
+func DisplayStr(x slang.LangType) string {
+	var b strings.Builder
+	Fprint(&b, x, Display)
+	return b.String()
+}
+
+func (p *printer) print(x slang.LangType) {
+	switch t := x.(type) {
+	case nil:
+		p.w.WriteString("nil")
+	case bool:
+		fmt.Fprint(p.w, t)
+	case slang.Str:
+		if p.mode == Display {
+			p.w.WriteString(string(t))
+		} else {
+			p.w.WriteString(t.String())
+		}
+	case slang.Char:
+		if p.mode == Display {
+			p.w.WriteRune(rune(t))
+		} else {
+			p.w.WriteString(t.String())
+		}
+	case slang.Symbol:
+		p.w.WriteString(string(t))
+	case slang.List:
+		p.printList(t)
+	case slang.Vector:
+		p.printVector(t)
+	case slang.Map:
+		p.printMap(t)
+	default:
+		fmt.Fprint(p.w, t)
+	}
+}
+
+// enter marks a vector as being printed. It returns false if the vector is already being
+// printed further up, in which case a cycle marker has been printed in its place.
+func (p *printer) enter(key vectorKey) bool {
+	if p.visiting[key] {
+		p.w.WriteString(cycleMarker)
+		return false
+	}
+	p.visiting[key] = true
+	return true
+}
+
+// printList prints the items of a List. Lists cannot contain themselves, but their items are
+// checked for cycles.
+func (p *printer) printList(lst slang.List) {
+	p.w.WriteByte('(')
+	for i, item := range lst.Items() {
+		if i > 0 {
+			p.w.WriteByte(' ')
+		}
+		p.print(item)
+	}
+	p.w.WriteByte(')')
+}
+
+func (p *printer) printMap(m slang.Map) {
+	p.w.WriteByte('{')
+	for i, key := range m.Keys() {
+		if i > 0 {
+			p.w.WriteByte(' ')
+		}
+		value, _ := m.Get(key)
+		p.print(key)
+		p.w.WriteByte(' ')
+		p.print(value)
+	}
+	p.w.WriteByte('}')
+}
+
+func (p *printer) printVector(vec slang.Vector) {
+	if len(vec) == 0 {
+		p.w.WriteString("[]")
+		return
+	}
+	// Vectors share backing arrays, so a Vector can contain itself if an item is assigned from Go
+	key := vectorKey{&vec[0], len(vec)}
+	if !p.enter(key) {
+		return
+	}
+	defer delete(p.visiting, key)
+
+	p.w.WriteByte('[')
+	for i, item := range vec {
+		if i > 0 {
+			p.w.WriteByte(' ')
+		}
+		p.print(item)
+	}
+	p.w.WriteByte(']')
+}
+
+// vectorKey identifies a Vector by its backing array and length.
+type vectorKey struct {
+	first *slang.LangType
+	len   int
+}
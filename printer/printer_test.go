@@ -0,0 +1,107 @@
+package printer
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/gen"
+	"github.com/zachorosz/slang/parser"
+)
+
+var printTests = []struct {
+	x        slang.LangType
+	readable string
+	display  string
+}{
+	{nil, "nil", "nil"},
+	{true, "true", "true"},
+	{slang.Number(1.5), "1.5", "1.5"},
+	{slang.Symbol("sym"), "sym", "sym"},
+	{slang.Str("hello"), "\"hello\"", "hello"},
+	{slang.Str("say \"hi\"\n"), "\"say \\\"hi\\\"\\n\"", "say \"hi\"\n"},
+	{slang.Str("a\\b\tc"), "\"a\\\\b\\tc\"", "a\\b\tc"},
+	{slang.Str("\x00"), "\"\\u{0}\"", "\x00"},
+	{slang.Char('a'), "\\a", "a"},
+	{slang.Char('\n'), "\\newline", "\n"},
+	{slang.Char(0), "\\u{0}", "\x00"},
+	{mustRegex(`a"\d`), "#\"a\\\"\\d\"", "#\"a\\\"\\d\""},
+	{slang.List{}, "()", "()"},
+	{slang.MakeList(slang.Symbol("f"), nil, false), "(f nil false)", "(f nil false)"},
+	{slang.MakeVector(slang.Str("a"), slang.MakeList(slang.Str("b"))), "[\"a\" (\"b\")]", "[a (b)]"},
+	{slang.Vector{}, "[]", "[]"},
+	{mustMap(slang.Str("a"), slang.MakeVector(slang.Str("b"))), "{\"a\" [\"b\"]}", "{a [b]}"},
+	{slang.Inst{Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}, "#inst \"2020-01-02T03:04:05Z\"", "#inst \"2020-01-02T03:04:05Z\""},
+	{slang.UUID{0xf8, 0x1d, 15: 0xf6}, "#uuid \"f81d0000-0000-0000-0000-0000000000f6\"", "#uuid \"f81d0000-0000-0000-0000-0000000000f6\""},
+}
+
+func mustMap(kvs ...slang.LangType) slang.Map {
+	m, err := slang.MakeMap(kvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func mustRegex(pattern string) slang.Regex {
+	re, err := slang.RePattern(slang.Str(pattern))
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+func TestPrint(t *testing.T) {
+	for _, test := range printTests {
+		if got := PrStr(test.x); got != test.readable {
+			t.Errorf("PrStr(%#v) == %q, want %q", test.x, got, test.readable)
+		}
+		if got := DisplayStr(test.x); got != test.display {
+			t.Errorf("DisplayStr(%#v) == %q, want %q", test.x, got, test.display)
+		}
+	}
+}
+
+func TestFprintln(t *testing.T) {
+	var b bytes.Buffer
+	if err := Fprintln(&b, slang.MakeVector(slang.Number(1), slang.Str("x")), Readable); err != nil {
+		t.Fatalf("Fprintln returned unexpected error %s", err)
+	}
+	if got, want := b.String(), "[1 \"x\"]\n"; got != want {
+		t.Errorf("Fprintln wrote %q, want %q", got, want)
+	}
+}
+
+func TestPrintCycle(t *testing.T) {
+	vec := slang.Vector{slang.Number(1), nil}
+	vec[1] = vec
+
+	if got, want := PrStr(vec), "[1 <cycle>]"; got != want {
+		t.Errorf("PrStr of cyclic vector == %q, want %q", got, want)
+	}
+
+	// the same vector printed twice side by side is not a cycle
+	inner := slang.MakeVector(slang.Number(1))
+	if got, want := PrStr(slang.MakeVector(inner, inner)), "[[1] [1]]"; got != want {
+		t.Errorf("PrStr of repeated vector == %q, want %q", got, want)
+	}
+}
+
+func TestReadableRoundTrip(t *testing.T) {
+	roundTrips := func(v gen.Value) bool {
+		printed := PrStr(v.LangType)
+		forms, err := parser.Parse("TestReadableRoundTrip", printed)
+		if err != nil {
+			t.Logf("Parse(%q) returned unexpected error %s", printed, err)
+			return false
+		}
+		return len(forms) == 1 && slang.Eq(forms[0], v.LangType)
+	}
+	config := &quick.Config{MaxCount: 1000, Rand: rand.New(rand.NewSource(1))}
+	if err := quick.Check(roundTrips, config); err != nil {
+		t.Error(err)
+	}
+}
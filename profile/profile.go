@@ -0,0 +1,245 @@
+// Package profile implements a profiler of slang programs. A Profiler is a slang.Hook that counts the
+// applications of each procedure and measures the time spent in it, and writes a profile in the
+// pprof format read by `go tool pprof`.
+//
+// The profiler counts rather than samples: every form evaluated while the Profiler is set is timed,
+// so programs run slower while they are profiled.
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zachorosz/slang"
+)
+
+// Profiler records the call stacks of an evaluation. Use New to construct a Profiler and set it with
+// Env.SetHook.
+type Profiler struct {
+	start   time.Time
+	last    time.Time // time of the last evaluation event
+	current *sample   // sample of the call stack at the last event
+
+	samples      map[string]*sample
+	order        []*sample           // samples in the order they were first seen
+	functions    map[function]uint64 // id of each function; ids index functionList from 1
+	locations    map[location]uint64
+	functionList []function
+	locationList []location
+}
+
+// function identifies a procedure: its name and the location of its definition.
+type function struct {
+	name slang.Symbol
+	file string
+	line int
+}
+
+// location is a line of a function.
+type location struct {
+	function uint64
+	line     int
+}
+
+// sample is the number of applications and the time spent with a call stack.
+type sample struct {
+	locations []uint64 // location ids, innermost first
+	calls     int64
+	nanos     int64
+}
+
+// New constructs a Profiler. Time is measured from the call to New.
+func New() *Profiler {
+	now := time.Now()
+	return &Profiler{
+		start:     now,
+		last:      now,
+		samples:   map[string]*sample{},
+		functions: map[function]uint64{},
+		locations: map[location]uint64{},
+	}
+}
+
+// Eval attributes the time since the last event to the call stack of that event.
+func (p *Profiler) Eval(expr slang.LangType, env slang.Env, stack []slang.Frame) error {
+	line := 0
+	if lst, isList := expr.(slang.List); isList {
+		if src, hasSource := lst.Source(); hasSource {
+			line = src.Line
+		}
+	}
+	p.record(stack, line, 0)
+	return nil
+}
+
+// Apply counts the application of the last frame of stack.
+func (p *Profiler) Apply(stack []slang.Frame) error {
+	p.record(stack, 0, 1)
+	return nil
+}
+
+// record ends the interval of the last event and starts one for stack. line is the line evaluated
+// by the innermost frame, or 0 if it is not known.
+func (p *Profiler) record(stack []slang.Frame, line int, calls int64) {
+	p.flush()
+	if len(stack) == 0 {
+		p.current = nil // top-level forms outside of procedures are not profiled
+		return
+	}
+	s := p.sample(stack, line)
+	s.calls += calls
+	p.current = s
+}
+
+// flush attributes the time since the last event to the current sample.
+func (p *Profiler) flush() {
+	now := time.Now()
+	if p.current != nil {
+		p.current.nanos += int64(now.Sub(p.last))
+	}
+	p.last = now
+}
+
+// sample returns the sample of a call stack, adding it if it has not been seen.
+func (p *Profiler) sample(stack []slang.Frame, line int) *sample {
+	ids := make([]uint64, len(stack))
+	var key strings.Builder
+	for i := range stack {
+		frame := stack[len(stack)-1-i]
+		fn := p.function(frame)
+		// a frame is at the line of the application of the frame it called
+		at := line
+		if i > 0 {
+			at = 0
+			if src, hasSource := stack[len(stack)-i].Form.Source(); hasSource {
+				at = src.Line
+			}
+		}
+		ids[i] = p.location(fn, at)
+		key.WriteString(strconv.FormatUint(ids[i], 10))
+		key.WriteByte(' ')
+	}
+
+	s, exists := p.samples[key.String()]
+	if !exists {
+		s = &sample{locations: ids}
+		p.samples[key.String()] = s
+		p.order = append(p.order, s)
+	}
+	return s
+}
+
+// function returns the id of the procedure of a frame.
+func (p *Profiler) function(frame slang.Frame) uint64 {
+	fn := function{name: frame.Name()}
+	if lambda, isLambda := frame.Procedure.(slang.Lambda); isLambda {
+		if src, hasSource := lambda.Source(); hasSource {
+			fn.file, fn.line = src.File, src.Line
+		}
+	}
+	id, exists := p.functions[fn]
+	if !exists {
+		p.functionList = append(p.functionList, fn)
+		id = uint64(len(p.functionList))
+		p.functions[fn] = id
+	}
+	return id
+}
+
+// location returns the id of a line of a function.
+func (p *Profiler) location(fn uint64, line int) uint64 {
+	loc := location{function: fn, line: line}
+	id, exists := p.locations[loc]
+	if !exists {
+		p.locationList = append(p.locationList, loc)
+		id = uint64(len(p.locationList))
+		p.locations[loc] = id
+	}
+	return id
+}
+
+// Write writes the profile recorded so far to w as a gzipped pprof protocol buffer. Each sample has
+// two values: the number of applications of its innermost procedure (calls/count) and the time spent
+// with its call stack (time/nanoseconds).
+func (p *Profiler) Write(w io.Writer) error {
+	p.flush()
+	p.current = nil
+
+	var b protoBuffer
+	strs := stringTable{index: map[string]int64{}}
+	strs.add("")
+
+	b.message(1, valueType(strs.add("calls"), strs.add("count")))
+	b.message(1, valueType(strs.add("time"), strs.add("nanoseconds")))
+	for _, s := range p.order {
+		var sb protoBuffer
+		sb.packed(1, s.locations)
+		sb.packed(2, []uint64{uint64(s.calls), uint64(s.nanos)})
+		b.message(2, sb)
+	}
+
+	// line 0 means the line is not known; pprof shows the start of the function instead
+	for i, loc := range p.locationList {
+		line := loc.line
+		if line == 0 {
+			line = p.functionList[loc.function-1].line
+		}
+		var lb, lineb protoBuffer
+		lineb.uint64(1, loc.function)
+		lineb.uint64(2, uint64(line))
+		lb.uint64(1, uint64(i+1))
+		lb.message(4, lineb)
+		b.message(4, lb)
+	}
+	for i, fn := range p.functionList {
+		var fb protoBuffer
+		fb.uint64(1, uint64(i+1))
+		fb.uint64(2, uint64(strs.add(string(fn.name))))
+		fb.uint64(3, uint64(strs.add(string(fn.name))))
+		fb.uint64(4, uint64(strs.add(fn.file)))
+		fb.uint64(5, uint64(fn.line))
+		b.message(5, fb)
+	}
+
+	// the string table is written last, after every string is added
+	for _, s := range strs.strings {
+		b.bytes(6, []byte(s))
+	}
+	b.uint64(9, uint64(p.start.UnixNano()))
+	b.uint64(10, uint64(p.last.Sub(p.start)))
+	b.message(11, valueType(strs.index["time"], strs.index["nanoseconds"]))
+	b.uint64(12, 1)
+	b.uint64(14, uint64(strs.index["time"]))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.buf); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func valueType(typ, unit int64) protoBuffer {
+	var b protoBuffer
+	b.uint64(1, uint64(typ))
+	b.uint64(2, uint64(unit))
+	return b
+}
+
+// stringTable is the string table of a profile. Messages refer to strings by index.
+type stringTable struct {
+	strings []string
+	index   map[string]int64
+}
+
+func (t *stringTable) add(s string) int64 {
+	i, exists := t.index[s]
+	if !exists {
+		i = int64(len(t.strings))
+		t.index[s] = i
+		t.strings = append(t.strings, s)
+	}
+	return i
+}
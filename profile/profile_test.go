@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/zachorosz/slang"
+	"github.com/zachorosz/slang/parser"
+)
+
+func TestProfiler(t *testing.T) {
+	env := slang.MakeEnv(nil)
+	env.Define(slang.Symbol("dec"), slang.Subroutine{Name: "dec",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return args[0].(slang.Number) - 1, nil },
+	})
+	env.Define(slang.Symbol("zero?"), slang.Subroutine{Name: "zero?",
+		Func: func(args ...slang.LangType) (slang.LangType, error) { return args[0] == slang.Number(0), nil },
+	})
+	exprs, err := parser.Parse("count.sl", "(define count [n]\n  (if (zero? n) n (count (dec n))))\n(count 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := New()
+	env.SetHook(p)
+	for _, expr := range exprs {
+		if _, err := slang.Evaluate(expr, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.SetHook(nil)
+
+	calls := map[string]int64{}
+	for _, s := range p.order {
+		fn := p.functionList[p.locationList[s.locations[0]-1].function-1]
+		calls[string(fn.name)] += s.calls
+	}
+	want := map[string]int64{"count": 4, "zero?": 4, "dec": 3}
+	for name, n := range want {
+		if calls[name] != n {
+			t.Errorf("\n%s:\n\tgot %d calls\n\texp %d", name, calls[name], n)
+		}
+	}
+	if fn := p.functionList[0]; fn.name != "count" || fn.file != "count.sl" || fn.line != 1 {
+		t.Errorf("\n%s:\n\tgot %+v\n\texp count defined at count.sl:1", "function", fn)
+	}
+
+	var b bytes.Buffer
+	if err := p.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatalf("profile is not gzipped: %s", err)
+	}
+	data, _ := ioutil.ReadAll(gz)
+	for _, s := range []string{"calls", "nanoseconds", "count", "count.sl"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("\n%s:\n\tgot %q\n\texp string table to contain %q", "Write", data, s)
+		}
+	}
+}
+
+func TestProtoBuffer(t *testing.T) {
+	var b protoBuffer
+	b.uint64(1, 150)
+	b.uint64(2, 0)
+	b.bytes(3, []byte("ab"))
+	b.packed(4, []uint64{1, 300})
+	want := []byte{0x08, 0x96, 0x01, 0x1a, 0x02, 'a', 'b', 0x22, 0x03, 0x01, 0xac, 0x02}
+	if !bytes.Equal(b.buf, want) {
+		t.Errorf("\n%s:\n\tgot % x\n\texp % x", "TestProtoBuffer", b.buf, want)
+	}
+}
@@ -0,0 +1,124 @@
+package slang
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Regex is a slang regular expression type compiled with Go's regexp package. Regex literals are
+// written as `#"pattern"`.
+type Regex struct {
+	*regexp.Regexp
+}
+
+// String returns the literal representation of the Regex. Double-quotes in the pattern are escaped
+// so that the literal can be read back by the parser.
+func (re Regex) String() string {
+	var b strings.Builder
+	b.WriteString(`#"`)
+	escaped := false
+	for _, r := range re.Regexp.String() {
+		if r == '"' && !escaped {
+			b.WriteByte('\\')
+		}
+		escaped = r == '\\' && !escaped
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// RePattern compiles a pattern into a Regex.
+// Usage: `(re-pattern s)`
+func RePattern(pattern Str) (Regex, error) {
+	re, err := regexp.Compile(string(pattern))
+	if err != nil {
+		return Regex{}, fmt.Errorf("Invalid regular expression: %s", err)
+	}
+	return Regex{re}, nil
+}
+
+// RegexP returns true if object is a Regex.
+// Usage: `(regex? x)`
+func RegexP(x LangType) bool {
+	_, isRegex := x.(Regex)
+	return isRegex
+}
+
+// captures converts submatches to a slang value. A match of a Regex without groups is returned as
+// a Str; otherwise a Vector of the whole match followed by each group is returned. Groups that did
+// not participate in the match are nil.
+func captures(re Regex, s string, indexes []int) LangType {
+	if indexes == nil {
+		return nil
+	}
+	if re.NumSubexp() == 0 {
+		return Str(s[indexes[0]:indexes[1]])
+	}
+	return groups(s, indexes)
+}
+
+func groups(s string, indexes []int) Vector {
+	vec := make(Vector, len(indexes)/2)
+	for i := range vec {
+		if start := indexes[2*i]; start >= 0 {
+			vec[i] = Str(s[start:indexes[2*i+1]])
+		}
+	}
+	return vec
+}
+
+// ReFind returns the first match of re in s, or nil if there is no match. If re has groups, the
+// match is a Vector of the whole match followed by the captures of each group.
+// Usage: `(re-find re s)`
+func ReFind(re Regex, s Str) LangType {
+	return captures(re, string(s), re.FindStringSubmatchIndex(string(s)))
+}
+
+// ReMatches returns the match of re if it matches the entirety of s, or nil otherwise. If re has
+// groups, the match is a Vector of the whole match followed by the captures of each group.
+// Usage: `(re-matches re s)`
+func ReMatches(re Regex, s Str) LangType {
+	indexes := re.FindStringSubmatchIndex(string(s))
+	if indexes == nil || indexes[0] != 0 || indexes[1] != len(s) {
+		// the leftmost match may be shorter than s; anchor the pattern to retry
+		anchored, err := regexp.Compile(`^(?:` + re.Regexp.String() + `)$`)
+		if err != nil {
+			return nil
+		}
+		indexes = anchored.FindStringSubmatchIndex(string(s))
+	}
+	return captures(re, string(s), indexes)
+}
+
+// ReSeq returns a Vector of every successive match of re in s. Each match has the same form as
+// returned by re-find.
+// Usage: `(re-seq re s)`
+func ReSeq(re Regex, s Str) Vector {
+	all := re.FindAllStringSubmatchIndex(string(s), -1)
+	matches := make(Vector, len(all))
+	for i, indexes := range all {
+		matches[i] = captures(re, string(s), indexes)
+	}
+	return matches
+}
+
+// ReGroups returns a Vector of the whole first match of re in s followed by the captures of each
+// group, or nil if there is no match. Unlike re-find, a Vector is returned even if re has no
+// groups.
+// Usage: `(re-groups re s)`
+func ReGroups(re Regex, s Str) LangType {
+	indexes := re.FindStringSubmatchIndex(string(s))
+	if indexes == nil {
+		return nil
+	}
+	return groups(string(s), indexes)
+}
+
+// ReReplace returns a copy of s with every match of re replaced by replacement. Within
+// replacement, $1 or ${name} refer to the captures of a group.
+// Usage: `(re-replace re s replacement)`
+func ReReplace(re Regex, s, replacement Str) Str {
+	return Str(re.ReplaceAllString(string(s), string(replacement)))
+}
@@ -0,0 +1,62 @@
+package slang
+
+import (
+	"testing"
+)
+
+func mustRegex(t *testing.T, pattern string) Regex {
+	t.Helper()
+	re, err := RePattern(Str(pattern))
+	if err != nil {
+		t.Fatalf("RePattern(%q) returned unexpected error %s", pattern, err)
+	}
+	return re
+}
+
+func TestRegexMatching(t *testing.T) {
+	cases := []struct {
+		name      string
+		got, want LangType
+	}{
+		{"ReFind", ReFind(mustRegex(t, `\d+`), "abc 123 456"), Str("123")},
+		{"ReFind groups", ReFind(mustRegex(t, `(\w+)=(\d+)?`), "x= y=2"), MakeVector(Str("x="), Str("x"), nil)},
+		{"ReFind no match", ReFind(mustRegex(t, `\d`), "abc"), nil},
+		{"ReMatches", ReMatches(mustRegex(t, `a|ab`), "ab"), Str("ab")},
+		{"ReMatches partial", ReMatches(mustRegex(t, `\d+`), "123a"), nil},
+		{"ReMatches groups", ReMatches(mustRegex(t, `(\d+)-(\d+)`), "1-2"), MakeVector(Str("1-2"), Str("1"), Str("2"))},
+		{"ReSeq", ReSeq(mustRegex(t, `\d`), "1a2b3"), MakeVector(Str("1"), Str("2"), Str("3"))},
+		{"ReSeq groups", ReSeq(mustRegex(t, `(\w)=(\d)`), "a=1 b=2"), MakeVector(
+			MakeVector(Str("a=1"), Str("a"), Str("1")),
+			MakeVector(Str("b=2"), Str("b"), Str("2")))},
+		{"ReSeq no match", ReSeq(mustRegex(t, `\d`), "abc"), Vector{}},
+		{"ReGroups", ReGroups(mustRegex(t, `\d+`), "a12"), MakeVector(Str("12"))},
+		{"ReReplace", ReReplace(mustRegex(t, `(\w+)@(\w+)`), "me@host", "$2:$1"), Str("host:me")},
+	}
+
+	for _, c := range cases {
+		if !Eq(c.got, c.want) {
+			t.Errorf("%s == %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestRegexString(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{`\d+`, `#"\d+"`},
+		{`say "hi"`, `#"say \"hi\""`},
+		{`\\"`, `#"\\\""`},
+	}
+
+	for _, c := range cases {
+		if got := mustRegex(t, c.pattern).String(); got != c.want {
+			t.Errorf("String() of %q == %s, want %s", c.pattern, got, c.want)
+		}
+	}
+
+	if _, err := RePattern("("); err == nil {
+		t.Errorf("RePattern(\"(\") returned no error")
+	}
+}
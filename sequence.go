@@ -1,6 +1,9 @@
 package slang
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Sequence is an interface for sequential composite types. Sequences are immutable collections that
 // are represented by its abstractions.
@@ -20,9 +23,25 @@ type node struct {
 // List is a sequence type that is implemented as a singly linked list, Lists have O(1) insertions
 // and O(n) access times (if not accessing the head). An empty list, `'()`, is also treated as nil.
 type List struct {
-	head *node
-	tail *node
-	len  int
+	head   *node
+	tail   *node
+	len    int
+	source *Source // location of a List read by the parser
+}
+
+// Source returns the location of the List in source code. Only Lists read by the parser have a
+// location.
+func (lst List) Source() (Source, bool) {
+	if lst.source == nil {
+		return Source{}, false
+	}
+	return *lst.source, true
+}
+
+// WithSource returns the List with its location in source code set to src.
+func (lst List) WithSource(src Source) List {
+	lst.source = &src
+	return lst
 }
 
 // Append - O(1) - returns a new copy of the List with a new item appended at the tail.
@@ -40,6 +59,7 @@ func (lst List) Append(obj LangType) Sequence {
 
 	lst.tail = n
 	lst.len++
+	lst.source = nil // a new list is not in the source
 
 	return lst
 }
@@ -71,6 +91,15 @@ func (lst List) Nth(n Number) LangType {
 	return node.value
 }
 
+// Items - O(n) - returns a slice of the items in the List.
+func (lst List) Items() []LangType {
+	items := make([]LangType, 0, lst.len)
+	for node := lst.head; node != nil; node = node.next {
+		items = append(items, node.value)
+	}
+	return items
+}
+
 // Len - returns the length of the List.
 func (lst List) Len() Number {
 	return Number(lst.len)
@@ -78,19 +107,16 @@ func (lst List) Len() Number {
 
 // String - returns a string with the external representation of the List.
 func (lst List) String() string {
-	items := ""
-	node := lst.head
-	counter := Number(0)
-	for node != nil {
-		if counter < lst.Len()-1 {
-			items += fmt.Sprintf("%s ", node.value)
-		} else {
-			items += fmt.Sprint(node.value)
+	var b strings.Builder
+	b.WriteByte('(')
+	for node := lst.head; node != nil; node = node.next {
+		if node != lst.head {
+			b.WriteByte(' ')
 		}
-		counter++
-		node = node.next
+		b.WriteString(repr(node.value))
 	}
-	return fmt.Sprintf("(%s)", items)
+	b.WriteByte(')')
+	return b.String()
 }
 
 // Vector is a sequence type that represents a contiguously allocated, dynamic array structure.
@@ -126,15 +152,24 @@ func (vec Vector) Len() Number {
 }
 
 func (vec Vector) String() string {
-	items := ""
+	var b strings.Builder
+	b.WriteByte('[')
 	for i, item := range vec {
-		if i < len(vec)-1 {
-			items += fmt.Sprintf("%s ", item)
-		} else {
-			items += fmt.Sprint(item)
+		if i > 0 {
+			b.WriteByte(' ')
 		}
+		b.WriteString(repr(item))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// repr returns the external representation of an item of a sequence.
+func repr(x LangType) string {
+	if x == nil {
+		return "nil"
 	}
-	return fmt.Sprintf("[%s]", items)
+	return fmt.Sprint(x)
 }
 
 // NilP is a predicate that returns true if object is nil or an empty list.
@@ -195,6 +230,22 @@ func Len(seq Sequence) (Number, error) {
 	return seq.Len(), nil
 }
 
+// ToSlice returns the items of a Sequence as a slice. Lists are walked once rather than through
+// repeated calls to Rest.
+func ToSlice(seq Sequence) []LangType {
+	switch t := seq.(type) {
+	case List:
+		return t.Items()
+	case Vector:
+		return t
+	}
+	items := make([]LangType, 0, int(seq.Len()))
+	for ; seq.Len() > 0; seq = seq.Rest() {
+		items = append(items, seq.First())
+	}
+	return items
+}
+
 // MakeList creates a new List from a given set of item(s).
 // Usage: `(list items...)`
 func MakeList(first LangType, rest ...LangType) List {
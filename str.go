@@ -0,0 +1,144 @@
+package slang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Append - O(n) - returns a new Str with a Char or Str appended. Other items are appended using the
+// default format verb from the fmt package.
+func (s Str) Append(obj LangType) Sequence {
+	switch t := obj.(type) {
+	case Char:
+		return s + Str(rune(t))
+	case Str:
+		return s + t
+	default:
+		return s + Str(fmt.Sprint(t))
+	}
+}
+
+// First - O(1) - returns the first Char of the Str, or nil if the Str is empty.
+func (s Str) First() LangType {
+	r, size := utf8.DecodeRuneInString(string(s))
+	if size == 0 {
+		return nil
+	}
+	return Char(r)
+}
+
+// Rest - O(1) - returns the Str without its first Char. If the Str is empty, an empty Str is
+// returned.
+func (s Str) Rest() Sequence {
+	_, size := utf8.DecodeRuneInString(string(s))
+	return s[size:]
+}
+
+// Nth - O(n) - accesses and returns the Nth (zero-based) Char in the Str.
+func (s Str) Nth(n Number) LangType {
+	return Char([]rune(string(s))[int(n)])
+}
+
+// Len - O(n) - returns the number of Chars in the Str.
+func (s Str) Len() Number {
+	return Number(utf8.RuneCountInString(string(s)))
+}
+
+// Substring returns the Chars of s from start up to, but not including, end.
+// Usage: `(substring s start end?)`
+func Substring(s Str, start, end Number) (Str, error) {
+	runes := []rune(string(s))
+	if start < 0 || end > Number(len(runes)) || start > end {
+		return "", fmt.Errorf("Substring range [%s, %s) out of bounds", start, end)
+	}
+	return Str(runes[int(start):int(end)]), nil
+}
+
+// Split returns a Vector of the substrings of s separated by sep.
+// Usage: `(split s sep)`
+func Split(s, sep Str) Vector {
+	parts := strings.Split(string(s), string(sep))
+	vec := make(Vector, len(parts))
+	for i, part := range parts {
+		vec[i] = Str(part)
+	}
+	return vec
+}
+
+// Trim returns s without leading and trailing whitespace.
+// Usage: `(trim s)`
+func Trim(s Str) Str {
+	return Str(strings.TrimSpace(string(s)))
+}
+
+// Upper returns s with all letters mapped to upper case.
+// Usage: `(upper s)`
+func Upper(s Str) Str {
+	return Str(strings.ToUpper(string(s)))
+}
+
+// Lower returns s with all letters mapped to lower case.
+// Usage: `(lower s)`
+func Lower(s Str) Str {
+	return Str(strings.ToLower(string(s)))
+}
+
+// IndexOf returns the (zero-based) Char index of the first instance of substr in s, or -1 if
+// substr is not present.
+// Usage: `(index-of s substr)`
+func IndexOf(s, substr Str) Number {
+	i := strings.Index(string(s), string(substr))
+	if i < 0 {
+		return -1
+	}
+	return Number(utf8.RuneCountInString(string(s[:i])))
+}
+
+// StartsWithP returns true if s begins with prefix.
+// Usage: `(starts-with? s prefix)`
+func StartsWithP(s, prefix Str) bool {
+	return strings.HasPrefix(string(s), string(prefix))
+}
+
+// EndsWithP returns true if s ends with suffix.
+// Usage: `(ends-with? s suffix)`
+func EndsWithP(s, suffix Str) bool {
+	return strings.HasSuffix(string(s), string(suffix))
+}
+
+// Replace returns a copy of s with every instance of old replaced by new.
+// Usage: `(replace s old new)`
+func Replace(s, old, new Str) Str {
+	return Str(strings.Replace(string(s), string(old), string(new), -1))
+}
+
+// StringToNumber converts a string to a number. Hex and scientific notations are accepted.
+// Usage: `(string->number s)`
+func StringToNumber(s Str) (Number, error) {
+	literal := strings.TrimSpace(string(s))
+	if n, err := strconv.ParseInt(literal, 0, 64); err == nil {
+		return Number(n), nil
+	}
+	n, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Cannot convert %s to a number", s)
+	}
+	return Number(n), nil
+}
+
+// NumberToString converts a number to a string.
+// Usage: `(number->string n)`
+func NumberToString(n Number) Str {
+	return Str(n.String())
+}
+
+// StringToSymbol converts a string to a symbol.
+// Usage: `(string->symbol s)`
+func StringToSymbol(s Str) (Symbol, error) {
+	if s == "" {
+		return "", fmt.Errorf("Cannot convert an empty string to a symbol")
+	}
+	return Symbol(s), nil
+}
@@ -0,0 +1,101 @@
+package slang
+
+import (
+	"testing"
+)
+
+func TestStrSequence(t *testing.T) {
+	s := Str("λx")
+	if got := s.Len(); got != 2 {
+		t.Errorf("Len(%s) == %s, want 2", s, got)
+	}
+	if got := s.First(); got != Char('λ') {
+		t.Errorf("First(%s) == %v, want \\λ", s, got)
+	}
+	if got := s.Rest(); got != Str("x") {
+		t.Errorf("Rest(%s) == %v, want \"x\"", s, got)
+	}
+	if got, _ := Nth(s, 1); got != Char('x') {
+		t.Errorf("Nth(%s, 1) == %v, want \\x", s, got)
+	}
+	if got := s.Append(Char('!')); got != Str("λx!") {
+		t.Errorf("Append(%s, \\!) == %v, want \"λx!\"", s, got)
+	}
+	if got := Str("").First(); got != nil {
+		t.Errorf("First(\"\") == %v, want nil", got)
+	}
+}
+
+func TestSubstring(t *testing.T) {
+	cases := []struct {
+		s          Str
+		start, end Number
+		want       Str
+		err        bool
+	}{
+		{"hello", 1, 3, "el", false},
+		{"hello", 0, 5, "hello", false},
+		{"héllo", 1, 2, "é", false},
+		{"hello", 3, 2, "", true},
+		{"hello", 0, 6, "", true},
+	}
+
+	for _, c := range cases {
+		got, err := Substring(c.s, c.start, c.end)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("Substring(%s, %s, %s) == %s, %v, want %s", c.s, c.start, c.end, got, err, c.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got := Split("a,b,,c", ",")
+	want := MakeVector(Str("a"), Str("b"), Str(""), Str("c"))
+	if !Eq(got, want) {
+		t.Errorf("Split(\"a,b,,c\", \",\") == %s, want %s", got, want)
+	}
+}
+
+func TestStringFunctions(t *testing.T) {
+	cases := []struct {
+		name      string
+		got, want LangType
+	}{
+		{"Trim", Trim(" \thi\n"), Str("hi")},
+		{"Upper", Upper("hi"), Str("HI")},
+		{"Lower", Lower("HI"), Str("hi")},
+		{"IndexOf", IndexOf("héllo", "llo"), Number(2)},
+		{"IndexOf missing", IndexOf("hello", "z"), Number(-1)},
+		{"StartsWithP", StartsWithP("hello", "he"), true},
+		{"EndsWithP", EndsWithP("hello", "he"), false},
+		{"Replace", Replace("a-b-c", "-", "+"), Str("a+b+c")},
+		{"NumberToString", NumberToString(1.5), Str("1.5")},
+	}
+
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("%s == %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestStringToNumber(t *testing.T) {
+	cases := []struct {
+		s    Str
+		want Number
+		err  bool
+	}{
+		{"42", 42, false},
+		{" -1.5 ", -1.5, false},
+		{"0x10", 16, false},
+		{"1e3", 1000, false},
+		{"forty-two", 0, true},
+	}
+
+	for _, c := range cases {
+		got, err := StringToNumber(c.s)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("StringToNumber(%s) == %s, %v, want %s", c.s, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package slang
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Inst is an instant in time. Insts are read from tagged literals like #inst "1985-04-12T23:20:50Z".
+type Inst struct {
+	time.Time
+}
+
+// instLayouts are the accepted formats of #inst literals, from most to least precise.
+var instLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04", "2006-01-02"}
+
+// ParseInst parses an RFC 3339 timestamp. A date without a time, like "1985-04-12", is midnight UTC.
+// Usage: `#inst "1985-04-12T23:20:50.52Z"`
+func ParseInst(s Str) (Inst, error) {
+	for _, layout := range instLayouts {
+		if t, err := time.Parse(layout, string(s)); err == nil {
+			return Inst{t}, nil
+		}
+	}
+	return Inst{}, fmt.Errorf("Invalid instant %s - expected an RFC 3339 timestamp", s)
+}
+
+// String returns the tagged literal of the Inst.
+func (inst Inst) String() string {
+	return fmt.Sprintf("#inst \"%s\"", inst.Format(time.RFC3339Nano))
+}
+
+// InstP returns true if object is an Inst.
+// Usage: `(inst? x)`
+func InstP(x LangType) bool {
+	_, isInst := x.(Inst)
+	return isInst
+}
+
+// UUID is a universally unique identifier. UUIDs are read from tagged literals like
+// #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+type UUID [16]byte
+
+// ParseUUID parses a UUID in its canonical 8-4-4-4-12 hexadecimal form.
+// Usage: `#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`
+func ParseUUID(s Str) (UUID, error) {
+	var uuid UUID
+	groups := strings.Split(string(s), "-")
+	if len(groups) != 5 || len(groups[0]) != 8 || len(groups[1]) != 4 || len(groups[2]) != 4 ||
+		len(groups[3]) != 4 || len(groups[4]) != 12 {
+		return uuid, fmt.Errorf("Invalid UUID %s - expected the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", s)
+	}
+	if _, err := hex.Decode(uuid[:], []byte(strings.Join(groups, ""))); err != nil {
+		return uuid, fmt.Errorf("Invalid UUID %s - %s", s, err)
+	}
+	return uuid, nil
+}
+
+// Canonical returns the UUID in its canonical 8-4-4-4-12 hexadecimal form.
+func (uuid UUID) Canonical() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// String returns the tagged literal of the UUID.
+func (uuid UUID) String() string {
+	return fmt.Sprintf("#uuid \"%s\"", uuid.Canonical())
+}
+
+// UUIDP returns true if object is a UUID.
+// Usage: `(uuid? x)`
+func UUIDP(x LangType) bool {
+	_, isUUID := x.(UUID)
+	return isUUID
+}
@@ -0,0 +1,53 @@
+package slang
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInst(t *testing.T) {
+	cases := []struct {
+		in   Str
+		want string
+	}{
+		{"2020-01-02T03:04:05Z", `#inst "2020-01-02T03:04:05Z"`},
+		{"2020-01-02T03:04:05.5+01:00", `#inst "2020-01-02T03:04:05.5+01:00"`},
+		{"2020-01-02T03:04", `#inst "2020-01-02T03:04:00Z"`},
+		{"2020-01-02", `#inst "2020-01-02T00:00:00Z"`},
+	}
+
+	for _, c := range cases {
+		inst, err := ParseInst(c.in)
+		if err != nil {
+			t.Errorf("ParseInst(%s) returned unexpected error %s", c.in, err)
+		} else if inst.String() != c.want {
+			t.Errorf("ParseInst(%s) == %s, want %s", c.in, inst, c.want)
+		}
+	}
+
+	if _, err := ParseInst("yesterday"); err == nil {
+		t.Errorf("ParseInst(\"yesterday\") did not return an error")
+	}
+
+	utc, _ := ParseInst("2020-01-02T03:00:00Z")
+	offset, _ := ParseInst("2020-01-02T04:00:00+01:00")
+	if !Eq(utc, offset) || Eq(utc, Inst{time.Time{}}) {
+		t.Errorf("Eq does not compare Insts by instant")
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	uuid, err := ParseUUID("F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6")
+	if err != nil {
+		t.Fatalf("ParseUUID returned unexpected error %s", err)
+	}
+	if want := "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"; uuid.Canonical() != want {
+		t.Errorf("Canonical() == %s, want %s", uuid.Canonical(), want)
+	}
+
+	for _, in := range []Str{"", "f81d4fae7dec11d0a76500a0c91e6bf6", "f81d4fae-7dec-11d0-a765-00a0c91e6bfg"} {
+		if _, err := ParseUUID(in); err == nil {
+			t.Errorf("ParseUUID(%s) did not return an error", in)
+		}
+	}
+}
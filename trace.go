@@ -0,0 +1,81 @@
+package slang
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// evaluateTrace evaluates a trace or untrace form. Each operand names a procedure to trace or stop
+// tracing. The list of names is returned.
+// Usage: `(trace procedure...)`
+func evaluateTrace(form List, env Env) (LangType, error) {
+	traced := form.First() == Symbol("trace")
+	names := form.Rest().(List)
+	for _, operand := range names.Items() {
+		symbol, isSymbol := operand.(Symbol)
+		if !isSymbol {
+			return nil, fmt.Errorf("Arguments to %s must be symbols", form.First())
+		}
+		scope := env.scope(symbol)
+		if scope == nil {
+			return nil, fmt.Errorf("Symbol '%s' is undefined", symbol)
+		}
+
+		value, _ := scope.lookup(symbol)
+		switch t := value.(type) {
+		case Lambda:
+			t.traced = traced
+			scope.Mutate(symbol, t)
+		case Subroutine:
+			t.traced = traced
+			scope.Mutate(symbol, t)
+		default:
+			return nil, fmt.Errorf("'%s' is not a procedure", symbol)
+		}
+	}
+	return names, nil
+}
+
+// scope returns the innermost environment in which symbol is defined, or nil.
+func (env *Env) scope(symbol Symbol) *Env {
+	for scope := env; scope != nil; scope = scope.outer {
+		if _, exists := scope.lookup(symbol); exists {
+			return scope
+		}
+	}
+	return nil
+}
+
+// traceApplication applies a traced procedure with apply. The application and its result are
+// written to the *err* port of env, indented by the number of traced applications in progress.
+func traceApplication(name Symbol, args []LangType, env Env, apply func() (LangType, error)) (LangType, error) {
+	w := traceWriter(env)
+	depth := 0
+	if env.hooks != nil {
+		depth = env.hooks.traceDepth
+		env.hooks.traceDepth++
+		defer func() { env.hooks.traceDepth-- }()
+	}
+	indent := strings.Repeat("  ", depth)
+
+	fmt.Fprintf(w, "%s> %s\n", indent, MakeList(name, args...))
+	result, err := apply()
+	if err != nil {
+		fmt.Fprintf(w, "%s! %s\n", indent, err)
+		return nil, err
+	}
+	fmt.Fprintf(w, "%s< %s\n", indent, result)
+	return result, nil
+}
+
+// traceWriter returns the writer of the *err* port of env, or standard error if there is none.
+func traceWriter(env Env) io.Writer {
+	if value, err := env.Get(ErrSymbol); err == nil {
+		if port, isPort := value.(*Port); isPort && port.writer != nil {
+			return port.writer
+		}
+	}
+	return os.Stderr
+}
@@ -0,0 +1,353 @@
+package slang
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Test is a test defined by deftest. Use Run to run it.
+type Test struct {
+	Name   Symbol
+	body   List
+	env    Env
+	source *Source
+}
+
+func (test Test) String() string {
+	return "<test>"
+}
+
+// Source returns the location of the deftest form that defined the Test. It returns false if the
+// form was not read from source.
+func (test Test) Source() (Source, bool) {
+	if test.source == nil {
+		return Source{}, false
+	}
+	return *test.source, true
+}
+
+// Run evaluates the body of the test. It returns the assertions of the body that failed, in the
+// order they failed; a failed assertion does not stop the test. Evaluation stops at other errors,
+// which are returned with the assertions that failed before them.
+func (test Test) Run() ([]*AssertionError, error) {
+	scope := MakeEnv(&test.env)
+	run := &testRun{}
+	tests := scope.tests
+	outer := tests.run
+	tests.run = run
+	defer func() { tests.run = outer }()
+
+	for _, expr := range test.body.Items() {
+		if _, err := Evaluate(expr, scope); err != nil {
+			if failure, isFailure := err.(*AssertionError); isFailure {
+				run.failures = append(run.failures, failure)
+				continue
+			}
+			return run.failures, err
+		}
+	}
+	return run.failures, nil
+}
+
+// testState is shared by an environment and every environment enclosed by it, like hookState, so
+// the assertions of procedures applied by a test find the running Test.
+type testState struct {
+	run      *testRun // the running Test, or nil
+	contexts []string // descriptions of the testing forms being evaluated
+}
+
+// testRun collects the failed assertions of a running Test.
+type testRun struct {
+	failures []*AssertionError
+}
+
+// AssertionError is the error of a failed is, assert=, are or for-all form.
+type AssertionError struct {
+	Source   Source   // location of the assertion; File is empty if it is not known
+	Form     LangType // the asserted expression
+	Message  string   // message of the assertion, if one was given
+	Contexts []string // descriptions of the enclosing testing forms, outermost first
+	// Compared is true if the assertion compared Expected to Actual: the arguments of assert=, or of
+	// an application of = asserted by is or are, expected first. Otherwise Actual is the value
+	// of Form, the negated application if Form applied a predicate, or the counterexample of a
+	// for-all: a Vector of its symbols and their values.
+	Compared bool
+	Expected LangType
+	Actual   LangType
+}
+
+func (err *AssertionError) Error() string {
+	var b strings.Builder
+	if err.Source.File != "" {
+		fmt.Fprintf(&b, "%s: ", err.Source)
+	}
+	fmt.Fprintf(&b, "Assertion failed: %v", err.Form)
+	if err.Message != "" {
+		fmt.Fprintf(&b, " - %s", err.Message)
+	}
+	return b.String()
+}
+
+// evaluateDeftest defines a Test.
+// Usage: `(deftest name body...)`
+func evaluateDeftest(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() < 2 {
+		return nil, fmt.Errorf("Invalid form for deftest")
+	}
+	name, isSymbol := operands.First().(Symbol)
+	if !isSymbol {
+		return nil, fmt.Errorf("First argument to deftest must be a symbol")
+	}
+
+	test := Test{Name: name, body: operands.Rest().(List), env: env, source: form.source}
+	env.Define(name, test)
+	return test, nil
+}
+
+// evaluateTesting evaluates a body and adds a description to the assertions that fail in it. The body
+// is not evaluated in tail position.
+// Usage: `(testing description body...)`
+func evaluateTesting(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() < 2 {
+		return nil, fmt.Errorf("Invalid form for testing")
+	}
+	description, isStr := operands.First().(Str)
+	if !isStr {
+		return nil, fmt.Errorf("First argument to testing must be a string")
+	}
+
+	if tests := env.tests; tests != nil {
+		tests.contexts = append(tests.contexts, string(description))
+		defer func() {
+			tests.contexts = tests.contexts[:len(tests.contexts)-1]
+		}()
+	}
+	var result LangType
+	var err error
+	for _, expr := range operands.Rest().(List).Items() {
+		if result, err = Evaluate(expr, env); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// evaluateIs asserts that an expression is true. Like assert=, an application of = is written with
+// the expected value first: (is (= 3 (f x))) reports 3 as expected and the value of (f x) as actual.
+// Usage: `(is expr message?)`
+func evaluateIs(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() < 1 || operands.Len() > 2 {
+		return nil, fmt.Errorf("Invalid form for is")
+	}
+	var message Str
+	if operands.Len() == 2 {
+		var isStr bool
+		if message, isStr = operands.Nth(1).(Str); !isStr {
+			return nil, fmt.Errorf("Second argument to is must be a string")
+		}
+	}
+	return assert(operands.First(), string(message), form, env)
+}
+
+// evaluateAssertEq asserts that two values are equal.
+// Usage: `(assert= expected actual)`
+func evaluateAssertEq(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() != 2 {
+		return nil, fmt.Errorf("Invalid form for assert=")
+	}
+	values, err := evaluateListItems(operands, env)
+	if err != nil {
+		return nil, err
+	}
+	if Eq(values[0], values[1]) {
+		return true, nil
+	}
+	return fail(env, failure(form, form, "", true, values[0], values[1]))
+}
+
+// evaluateAre asserts a template expression for each group of arguments. The parameters of the
+// template are replaced by the arguments of a group before the expression is asserted, like is.
+// Usage: `(are [params...] expr args...)`
+func evaluateAre(form List, env Env) (LangType, error) {
+	operands := form.Rest().(List)
+	if operands.Len() < 2 {
+		return nil, fmt.Errorf("Invalid form for are")
+	}
+	params, isVec := operands.First().(Vector)
+	if !isVec || len(params) == 0 {
+		return nil, fmt.Errorf("First argument to are must be a vector of symbols")
+	}
+	for _, param := range params {
+		if _, isSymbol := param.(Symbol); !isSymbol {
+			return nil, fmt.Errorf("First argument to are must be a vector of symbols")
+		}
+	}
+	template := operands.Nth(1)
+	args := operands.Rest().(List).Rest().(List).Items()
+	if len(args)%len(params) != 0 {
+		return nil, fmt.Errorf("Number of arguments to are must be a multiple of the number of parameters")
+	}
+
+	// while a Test is running, each group that fails is reported
+	passed := true
+	for start := 0; start < len(args); start += len(params) {
+		bindings := map[Symbol]LangType{}
+		for i, param := range params {
+			bindings[param.(Symbol)] = args[start+i]
+		}
+		result, err := assert(substitute(template, bindings), "", form, env)
+		if err != nil {
+			return nil, err
+		}
+		passed = passed && result == true
+	}
+	return passed, nil
+}
+
+// substitute replaces the symbols of expr bound in bindings. Quoted forms are not changed, and the
+// parameters of a procedure shadow the bindings in its definition.
+func substitute(expr LangType, bindings map[Symbol]LangType) LangType {
+	switch t := expr.(type) {
+	case Symbol:
+		if value, isBound := bindings[t]; isBound {
+			return value
+		}
+	case List:
+		items := t.Items()
+		if len(items) == 0 {
+			return expr
+		}
+		switch items[0] {
+		case Symbol("quote"):
+			return expr
+		case Symbol("lambda"):
+			bindings = shadow(bindings, items[1:])
+		case Symbol("define"):
+			if len(items) > 3 {
+				bindings = shadow(bindings, items[2:])
+			}
+		}
+		lst := List{}
+		for _, item := range items {
+			lst = lst.Append(substitute(item, bindings)).(List)
+		}
+		return lst
+	case Vector:
+		vec := make(Vector, len(t))
+		for i, item := range t {
+			vec[i] = substitute(item, bindings)
+		}
+		return vec
+	case Map:
+		// keys are literals and are not evaluated
+		values := make([]LangType, len(t.values))
+		for i, value := range t.values {
+			values[i] = substitute(value, bindings)
+		}
+		return Map{keys: t.keys, values: values, index: t.index}
+	}
+	return expr
+}
+
+// shadow returns bindings without the parameters of a procedure, the Vector that starts operands.
+func shadow(bindings map[Symbol]LangType, operands []LangType) map[Symbol]LangType {
+	if len(operands) == 0 {
+		return bindings
+	}
+	params, isVec := operands[0].(Vector)
+	if !isVec {
+		return bindings
+	}
+	shadowed := map[Symbol]LangType{}
+	for symbol, value := range bindings {
+		shadowed[symbol] = value
+	}
+	for _, param := range params {
+		if symbol, isSymbol := param.(Symbol); isSymbol {
+			delete(shadowed, symbol)
+		}
+	}
+	return shadowed
+}
+
+// assert evaluates expr and returns an *AssertionError located at form if it is not true. If expr
+// applies a procedure, the arguments are reported: as the expected and actual values for =, or in
+// the negated application for other predicates.
+func assert(expr LangType, message string, form List, env Env) (LangType, error) {
+	call, isApplication := expr.(List)
+	var operator Symbol
+	if isApplication && call.Len() > 1 {
+		operator, isApplication = call.First().(Symbol)
+	}
+	if !isApplication || operator == "" || isSpecialForm(operator) {
+		result, err := Evaluate(expr, env)
+		if err != nil {
+			return nil, err
+		}
+		if result != true {
+			return fail(env, failure(form, expr, message, false, nil, result))
+		}
+		return true, nil
+	}
+
+	args, err := evaluateListItems(call.Rest().(List), env)
+	if err != nil {
+		return nil, err
+	}
+	// the arguments are quoted so they are not evaluated again
+	application := MakeList(operator)
+	for _, arg := range args {
+		application = application.Append(MakeList(Symbol("quote"), arg)).(List)
+	}
+	result, err := Evaluate(application, env)
+	if err != nil {
+		return nil, err
+	}
+	if result == true {
+		return true, nil
+	}
+	if operator == Symbol("=") && len(args) == 2 {
+		return fail(env, failure(form, expr, message, true, args[0], args[1]))
+	}
+	return fail(env, failure(form, expr, message, false, nil,
+		MakeList(Symbol("not"), MakeList(operator, args...))))
+}
+
+// fail returns a failed assertion as an error. While a Test is running, the failure is collected
+// instead and the assertion evaluates to false, so the test goes on to report later failures.
+func fail(env Env, failure *AssertionError) (LangType, error) {
+	if env.tests == nil {
+		return nil, failure
+	}
+	failure.Contexts = append([]string(nil), env.tests.contexts...)
+	if env.tests.run == nil {
+		return nil, failure
+	}
+	env.tests.run.failures = append(env.tests.run.failures, failure)
+	return false, nil
+}
+
+func failure(form List, expr LangType, message string, compared bool, expected, actual LangType) *AssertionError {
+	src, _ := form.Source()
+	return &AssertionError{
+		Source:   src,
+		Form:     expr,
+		Message:  message,
+		Compared: compared,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+func isSpecialForm(symbol Symbol) bool {
+	for _, form := range SpecialForms {
+		if form == symbol {
+			return true
+		}
+	}
+	return false
+}